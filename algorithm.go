@@ -1,5 +1,100 @@
 package httpsig
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Algorithm names registered in the HTTP Signature Algorithms registry
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-initial-contents
+const (
+	AlgorithmRsaPssSha512    = "rsa-pss-sha512"
+	AlgorithmRsaV15Sha256    = "rsa-v1_5-sha256"
+	AlgorithmHmacSha256      = "hmac-sha256"
+	AlgorithmEcdsaP256Sha256 = "ecdsa-p256-sha256"
+	AlgorithmEcdsaP384Sha384 = "ecdsa-p384-sha384"
+	AlgorithmEd25519         = "ed25519"
+)
+
+// rfc9421Algorithms is the set of registered algorithm names
+var rfc9421Algorithms = map[string]struct{}{
+	AlgorithmRsaPssSha512:    {},
+	AlgorithmRsaV15Sha256:    {},
+	AlgorithmHmacSha256:      {},
+	AlgorithmEcdsaP256Sha256: {},
+	AlgorithmEcdsaP384Sha384: {},
+	AlgorithmEd25519:         {},
+}
+
+// JWS algorithm names which can be used instead of the RFC 9421 algorithm
+// names when both sides agree on them out of band
+//
+// https://datatracker.ietf.org/doc/html/rfc7518#section-3.1
+const (
+	JWSAlgorithmES256 = "ES256"
+	JWSAlgorithmES384 = "ES384"
+	JWSAlgorithmPS512 = "PS512"
+	JWSAlgorithmRS256 = "RS256"
+	JWSAlgorithmEdDSA = "EdDSA"
+	JWSAlgorithmHS256 = "HS256"
+)
+
+// jwsAlgorithms maps JWS algorithm names to the equivalent RFC 9421 algorithm names
+var jwsAlgorithms = map[string]string{
+	JWSAlgorithmES256: AlgorithmEcdsaP256Sha256,
+	JWSAlgorithmES384: AlgorithmEcdsaP384Sha384,
+	JWSAlgorithmPS512: AlgorithmRsaPssSha512,
+	JWSAlgorithmRS256: AlgorithmRsaV15Sha256,
+	JWSAlgorithmEdDSA: AlgorithmEd25519,
+	JWSAlgorithmHS256: AlgorithmHmacSha256,
+}
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown algorithm")
+
+	// ErrNilKey is returned when an algorithm is created from a nil key
+	// including a typed nil such as (*ecdsa.PublicKey)(nil)
+	ErrNilKey = errors.New("key is nil")
+
+	// ErrJWSAlgParameter is returned when the alg signature parameter is used
+	// with a JWS algorithm
+	//
+	// https://datatracker.ietf.org/doc/html/rfc9421#section-3.3.7
+	ErrJWSAlgParameter = errors.New("alg signature parameter must not be used with JWS algorithms")
+)
+
+// IsJWSAlgorithm reports whether name is a supported JWS algorithm name
+func IsJWSAlgorithm(name string) bool {
+	_, ok := jwsAlgorithms[name]
+	return ok
+}
+
+// AlgorithmNameFromJWS returns the RFC 9421 algorithm name
+// producing the same signatures as the JWS algorithm
+func AlgorithmNameFromJWS(jws string) (string, error) {
+	name, ok := jwsAlgorithms[jws]
+	if !ok {
+		return "", fmt.Errorf("%w: JWS algorithm %q", ErrUnknownAlgorithm, jws)
+	}
+
+	return name, nil
+}
+
+// JWSAlgorithmName returns the JWS algorithm name
+// producing the same signatures as the RFC 9421 algorithm
+func JWSAlgorithmName(name string) (string, error) {
+	for jws, n := range jwsAlgorithms {
+		if n == name {
+			return jws, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: no JWS algorithm for %q", ErrUnknownAlgorithm, name)
+}
+
 type Algorithm interface {
 	// The algorithm name which is also the value used
 	// for the alg signature parameter
@@ -7,3 +102,101 @@ type Algorithm interface {
 	// https://datatracker.ietf.org/doc/html/rfc9421#name-initial-contents
 	Name() string
 }
+
+// AlgorithmFactory creates an Algorithm from a key.
+// It returns an error if the key is not of the expected type.
+type AlgorithmFactory func(key any) (Algorithm, error)
+
+// AlgorithmRegistry maps algorithm names to factories creating
+// signing and verifying algorithms. It is safe for concurrent use.
+//
+// The signer and verifier packages register the built-in algorithms
+// in DefaultAlgorithmRegistry.
+type AlgorithmRegistry struct {
+	mu        sync.RWMutex
+	signing   map[string]AlgorithmFactory
+	verifying map[string]AlgorithmFactory
+}
+
+// DefaultAlgorithmRegistry is the registry used when none is specified
+var DefaultAlgorithmRegistry = NewAlgorithmRegistry()
+
+func NewAlgorithmRegistry() *AlgorithmRegistry {
+	return &AlgorithmRegistry{
+		signing:   make(map[string]AlgorithmFactory),
+		verifying: make(map[string]AlgorithmFactory),
+	}
+}
+
+// RegisterSigning registers the factory for a signing algorithm,
+// replacing any factory already registered with the same name
+func (r *AlgorithmRegistry) RegisterSigning(name string, factory AlgorithmFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.signing[name] = factory
+}
+
+// RegisterVerifying registers the factory for a verifying algorithm,
+// replacing any factory already registered with the same name
+func (r *AlgorithmRegistry) RegisterVerifying(name string, factory AlgorithmFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.verifying[name] = factory
+}
+
+// NewSigning creates the signing algorithm registered as name
+func (r *AlgorithmRegistry) NewSigning(name string, key any) (Algorithm, error) {
+	r.mu.RLock()
+	factory, ok := r.signing[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, name)
+	}
+
+	return factory(key)
+}
+
+// NewVerifying creates the verifying algorithm registered as name
+func (r *AlgorithmRegistry) NewVerifying(name string, key any) (Algorithm, error) {
+	r.mu.RLock()
+	factory, ok := r.verifying[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, name)
+	}
+
+	return factory(key)
+}
+
+// Names returns the sorted names of the registered algorithms
+func (r *AlgorithmRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.verifying))
+	for name := range r.signing {
+		names = append(names, name)
+	}
+	for name := range r.verifying {
+		if _, ok := r.signing[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// RegisterSigningAlgorithm registers a signing algorithm in DefaultAlgorithmRegistry
+func RegisterSigningAlgorithm(name string, factory AlgorithmFactory) {
+	DefaultAlgorithmRegistry.RegisterSigning(name, factory)
+}
+
+// RegisterVerifyingAlgorithm registers a verifying algorithm in DefaultAlgorithmRegistry
+func RegisterVerifyingAlgorithm(name string, factory AlgorithmFactory) {
+	DefaultAlgorithmRegistry.RegisterVerifying(name, factory)
+}
@@ -0,0 +1,79 @@
+package httpsig_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/stretchr/testify/assert"
+)
+
+type customAlgorithm struct {
+	key string
+}
+
+func (alg customAlgorithm) Name() string {
+	return "custom"
+}
+
+func TestAlgorithmRegistry(t *testing.T) {
+	r := httpsig.NewAlgorithmRegistry()
+
+	_, err := r.NewVerifying("custom", "key")
+	assert.ErrorIs(t, err, httpsig.ErrUnknownAlgorithm)
+
+	factory := func(key any) (httpsig.Algorithm, error) {
+		k, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+		return customAlgorithm{key: k}, nil
+	}
+	r.RegisterVerifying("custom", factory)
+
+	alg, err := r.NewVerifying("custom", "key")
+	assert.NoError(t, err)
+	assert.Equal(t, customAlgorithm{key: "key"}, alg)
+
+	_, err = r.NewVerifying("custom", 1)
+	assert.Error(t, err)
+
+	_, err = r.NewSigning("custom", "key")
+	assert.ErrorIs(t, err, httpsig.ErrUnknownAlgorithm)
+
+	r.RegisterSigning("custom", factory)
+	r.RegisterSigning("other", factory)
+	assert.Equal(t, []string{"custom", "other"}, r.Names())
+}
+
+func TestJWSAlgorithmNames(t *testing.T) {
+	tests := map[string]string{
+		httpsig.JWSAlgorithmES256: httpsig.AlgorithmEcdsaP256Sha256,
+		httpsig.JWSAlgorithmES384: httpsig.AlgorithmEcdsaP384Sha384,
+		httpsig.JWSAlgorithmPS512: httpsig.AlgorithmRsaPssSha512,
+		httpsig.JWSAlgorithmRS256: httpsig.AlgorithmRsaV15Sha256,
+		httpsig.JWSAlgorithmEdDSA: httpsig.AlgorithmEd25519,
+		httpsig.JWSAlgorithmHS256: httpsig.AlgorithmHmacSha256,
+	}
+
+	for jws, name := range tests {
+		t.Run(jws, func(t *testing.T) {
+			assert.True(t, httpsig.IsJWSAlgorithm(jws))
+			assert.False(t, httpsig.IsJWSAlgorithm(name))
+
+			got, err := httpsig.AlgorithmNameFromJWS(jws)
+			assert.NoError(t, err)
+			assert.Equal(t, name, got)
+
+			got, err = httpsig.JWSAlgorithmName(name)
+			assert.NoError(t, err)
+			assert.Equal(t, jws, got)
+		})
+	}
+
+	_, err := httpsig.AlgorithmNameFromJWS("ES512")
+	assert.ErrorIs(t, err, httpsig.ErrUnknownAlgorithm)
+
+	_, err = httpsig.JWSAlgorithmName("custom")
+	assert.ErrorIs(t, err, httpsig.ErrUnknownAlgorithm)
+}
@@ -0,0 +1,168 @@
+package httpsig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dunglas/httpsfv"
+)
+
+// Component parameters
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-http-signature-component-pa
+const (
+	ComponentParameterStructuredField = "sf"
+	ComponentParameterKey             = "key"
+	ComponentParameterByteSequence    = "bs"
+	ComponentParameterRequest         = "req"
+	ComponentParameterTrailer         = "tr"
+	ComponentParameterName            = "name"
+)
+
+// ComponentIdentifier identifies a component by its name and parameters,
+// e.g. "@query-param";name="var" or "example-dict";key="a"
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#section-2
+type ComponentIdentifier struct {
+	// Name is the lowercased field name or the derived component name
+	Name   string
+	Params *httpsfv.Params
+}
+
+// NewComponentIdentifier creates a ComponentIdentifier without parameters.
+// Field names are lowercased.
+func NewComponentIdentifier(name string) ComponentIdentifier {
+	return ComponentIdentifier{
+		Name:   strings.ToLower(name),
+		Params: httpsfv.NewParams(),
+	}
+}
+
+// NewComponentIdentifiers creates a ComponentIdentifier without parameters for each name
+func NewComponentIdentifiers(names ...string) []ComponentIdentifier {
+	identifiers := make([]ComponentIdentifier, len(names))
+	for i, name := range names {
+		identifiers[i] = NewComponentIdentifier(name)
+	}
+
+	return identifiers
+}
+
+// ComponentIdentifierFromItem creates a ComponentIdentifier from
+// a String item, e.g. from the inner list of Signature-Input
+func ComponentIdentifierFromItem(item httpsfv.Item) (ComponentIdentifier, error) {
+	name, ok := item.Value.(string)
+	if !ok {
+		return ComponentIdentifier{}, fmt.Errorf("invalid component identifier: %v is not a string", item.Value)
+	}
+	if name != strings.ToLower(name) {
+		return ComponentIdentifier{}, fmt.Errorf("invalid component identifier: %q is not lowercase", name)
+	}
+
+	c := NewComponentIdentifier(name)
+	if item.Params != nil {
+		for _, param := range item.Params.Names() {
+			v, _ := item.Params.Get(param)
+			c.Params.Add(param, v)
+		}
+	}
+
+	return c, nil
+}
+
+// ParseComponentIdentifier parses a serialised component identifier,
+// e.g. "@query-param";name="var"
+func ParseComponentIdentifier(s string) (ComponentIdentifier, error) {
+	item, err := httpsfv.UnmarshalItem([]string{s})
+	if err != nil {
+		return ComponentIdentifier{}, fmt.Errorf("invalid component identifier %s: %w", s, err)
+	}
+
+	return ComponentIdentifierFromItem(item)
+}
+
+// WithParam returns a copy of the ComponentIdentifier with the parameter added
+func (c ComponentIdentifier) WithParam(name string, value any) ComponentIdentifier {
+	clone := NewComponentIdentifier(c.Name)
+	for _, param := range c.paramNames() {
+		v, _ := c.Params.Get(param)
+		clone.Params.Add(param, v)
+	}
+	clone.Params.Add(name, value)
+
+	return clone
+}
+
+// Param returns the value of the parameter
+func (c ComponentIdentifier) Param(name string) (any, bool) {
+	if c.Params == nil {
+		return nil, false
+	}
+
+	return c.Params.Get(name)
+}
+
+// HasParam reports whether the parameter is present
+func (c ComponentIdentifier) HasParam(name string) bool {
+	_, ok := c.Param(name)
+	return ok
+}
+
+// Item returns the ComponentIdentifier as an Item for the
+// inner list of @signature-params
+func (c ComponentIdentifier) Item() httpsfv.Item {
+	item := httpsfv.NewItem(c.Name)
+	for _, param := range c.paramNames() {
+		v, _ := c.Params.Get(param)
+		item.Params.Add(param, v)
+	}
+
+	return item
+}
+
+// Marshal serialises the ComponentIdentifier as a String item with parameters
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#section-2-4
+func (c ComponentIdentifier) Marshal() (string, error) {
+	return httpsfv.Marshal(c.Item())
+}
+
+// String returns the serialised ComponentIdentifier
+// or the name if it cannot be serialised
+func (c ComponentIdentifier) String() string {
+	s, err := c.Marshal()
+	if err != nil {
+		return c.Name
+	}
+
+	return s
+}
+
+// Equal reports whether both have the same name and parameters in the same order
+func (c ComponentIdentifier) Equal(other ComponentIdentifier) bool {
+	return c.String() == other.String()
+}
+
+func (c ComponentIdentifier) paramNames() []string {
+	if c.Params == nil {
+		return nil
+	}
+
+	return c.Params.Names()
+}
+
+// QueryParamComponent returns the "@query-param" component identifier
+// for the decoded query parameter name, e.g. "@query-param";name="var"
+func QueryParamComponent(name string) ComponentIdentifier {
+	return NewComponentIdentifier(DerivedComponentQueryParam).WithParam(ComponentParameterName, EncodeQueryParam(name))
+}
+
+// DictionaryMemberComponent returns the component identifier for the member
+// of a Dictionary header, e.g. "example-dict";key="a"
+func DictionaryMemberComponent(header string, key string) ComponentIdentifier {
+	return NewComponentIdentifier(header).WithParam(ComponentParameterKey, key)
+}
+
+func isDerivedComponent(name string) bool {
+	return strings.HasPrefix(name, "@")
+}
@@ -0,0 +1,95 @@
+package httpsig_test
+
+import (
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/dunglas/httpsfv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentIdentifier_Marshal(t *testing.T) {
+	tests := []struct {
+		identifier httpsig.ComponentIdentifier
+		expected   string
+	}{
+		{httpsig.NewComponentIdentifier("@method"), `"@method"`},
+		{httpsig.NewComponentIdentifier("Content-Digest"), `"content-digest"`},
+		{httpsig.NewComponentIdentifier("example-dict").WithParam(httpsig.ComponentParameterKey, "a"), `"example-dict";key="a"`},
+		{httpsig.NewComponentIdentifier("example-dict").WithParam(httpsig.ComponentParameterStructuredField, true), `"example-dict";sf`},
+		{httpsig.NewComponentIdentifier("@authority").WithParam(httpsig.ComponentParameterRequest, true), `"@authority";req`},
+		{httpsig.QueryParamComponent("Pet"), `"@query-param";name="Pet"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			s, err := tt.identifier.Marshal()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, s)
+
+			parsed, err := httpsig.ParseComponentIdentifier(s)
+			assert.NoError(t, err)
+			assert.True(t, tt.identifier.Equal(parsed))
+		})
+	}
+}
+
+func TestComponentIdentifier_WithParam(t *testing.T) {
+	c := httpsig.NewComponentIdentifier("example-dict")
+	withKey := c.WithParam(httpsig.ComponentParameterKey, "a")
+
+	assert.False(t, c.HasParam(httpsig.ComponentParameterKey))
+	assert.True(t, withKey.HasParam(httpsig.ComponentParameterKey))
+	assert.False(t, c.Equal(withKey))
+
+	key, ok := withKey.Param(httpsig.ComponentParameterKey)
+	assert.True(t, ok)
+	assert.Equal(t, "a", key)
+}
+
+func TestParseComponentIdentifier_Invalid(t *testing.T) {
+	for _, s := range []string{`"Content-Type"`, `@method`, `1`, `"@method";`} {
+		_, err := httpsig.ParseComponentIdentifier(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestSignatureInput_Components(t *testing.T) {
+	d, err := httpsfv.UnmarshalDictionary([]string{`sig1=("@method" "example-dict";key="a" "example-dict";sf "@query-param";name="Pet");created=1618884473`})
+	assert.NoError(t, err)
+
+	components, err := httpsig.SignatureInput(*d).Components()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`"@method"`, `"example-dict";key="a"`, `"example-dict";sf`, `"@query-param";name="Pet"`}, componentStrings(components))
+}
+
+func TestSignatureInput_Components_Invalid(t *testing.T) {
+	tests := []string{
+		`sig1=("@method" 1)`,
+		`sig1=("@method" "Content-Type")`,
+		`sig1=("@method" :YQ==:)`,
+		`sig1="@method"`,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			sigInput, err := httpsig.ParseSignatureInput(tt)
+			assert.NoError(t, err)
+
+			_, err = sigInput.Components()
+			assert.ErrorIs(t, err, httpsig.ErrInvalidSignatureInput)
+		})
+	}
+
+	_, err := httpsig.SignatureInput{}.Components()
+	assert.ErrorIs(t, err, httpsig.ErrInvalidSignatureInput)
+}
+
+func componentStrings(components []httpsig.ComponentIdentifier) []string {
+	s := make([]string, len(components))
+	for i, c := range components {
+		s[i] = c.String()
+	}
+
+	return s
+}
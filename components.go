@@ -3,6 +3,7 @@ package httpsig
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -25,6 +26,9 @@ const (
 
 var (
 	ErrMultipleQueryParamValues = errors.New("multiple query param values")
+	ErrHeaderNotFound           = errors.New("header not found")
+	ErrInvalidDictionary        = errors.New("invalid dictionary")
+	ErrDictionaryMemberNotFound = errors.New("dictionary member not found")
 )
 
 type Component struct {
@@ -48,10 +52,19 @@ func (sp *SignatureParams) Marshal() (string, error) {
 // GetComponentValue returns a component's value.
 // If the name starts with "@" then it is retrieved as a derived component
 // otherwise it is retrieved from the headers.
-func GetComponentValue(name string, msg HttpMessage) (string, error) {
+func GetComponentValue(c ComponentIdentifier, msg HttpMessage) (string, error) {
+	return getComponentValue(c, msg, DefaultStructuredFieldRegistry)
+}
+
+func getComponentValue(c ComponentIdentifier, msg HttpMessage, structuredFields *StructuredFieldRegistry) (string, error) {
 	var err error
-	val := ""
+	name := c.Name
 
+	if err := validateComponentParams(c); err != nil {
+		return "", err
+	}
+
+	val := ""
 	if isDerivedComponent(name) {
 		switch {
 		case name == DerivedComponentMethod:
@@ -65,10 +78,16 @@ func GetComponentValue(name string, msg HttpMessage) (string, error) {
 		case name == DerivedComponentRequestTarget:
 			val, err = msg.Url().RequestURI(), nil
 		case name == DerivedComponentPath:
+			val, err = GetPathComponentValue(msg), nil
 		case name == DerivedComponentQuery:
 			val, err = msg.Url().RawQuery, nil
-		case strings.HasPrefix(name, DerivedComponentQueryParam):
-			val, err = GetQueryParamComponentValue(name, msg)
+		case name == DerivedComponentQueryParam:
+			queryName, ok := c.Param(ComponentParameterName)
+			if queryName, isString := queryName.(string); ok && isString {
+				val, err = GetQueryParamComponentValue(queryName, msg)
+			} else {
+				err = fmt.Errorf("%s requires the name parameter", DerivedComponentQueryParam)
+			}
 		case name == DerivedComponentStatus:
 			if _, ok := msg.(*HttpRequest); ok {
 				return "", fmt.Errorf("request do not support @status")
@@ -78,54 +97,167 @@ func GetComponentValue(name string, msg HttpMessage) (string, error) {
 		default:
 			err = fmt.Errorf("unknown derived component: %s", name)
 		}
-	} else if header := msg.Header().Get(name); header != "" {
-		val, err = header, nil
 	} else {
-		err = fmt.Errorf("header '%s' not found in the http message", name)
+		val, err = getHeaderComponentValue(c, msg, structuredFields)
 	}
 
 	return val, err
 }
 
-// GetQueryParamComponentValue returns the value of a "@query-param" derived component.
-// name is expected to have the name of the query parameter, e.g. "@query-param";name="var".
-// TODO: handle url encoded @query-param name
-func GetQueryParamComponentValue(name string, msg HttpMessage) (string, error) {
-	s := name[strings.Index(name, ";")+1:]
-	parts := strings.SplitN(s, "=", 2)
+// validateComponentParams returns an error if a parameter
+// is not supported by the component. The "sf" and "key" parameters
+// cannot be combined as Dictionary members are always strictly serialised.
+func validateComponentParams(c ComponentIdentifier) error {
+	if c.HasParam(ComponentParameterStructuredField) && c.HasParam(ComponentParameterKey) {
+		return fmt.Errorf("component parameters %q and %q of %s cannot be combined", ComponentParameterStructuredField, ComponentParameterKey, c.Name)
+	}
+
+	for _, param := range c.paramNames() {
+		v, _ := c.Param(param)
 
-	key, queryName := "", ""
-	if len(parts) > 0 {
-		key = parts[0]
+		switch {
+		case c.Name == DerivedComponentQueryParam && param == ComponentParameterName:
+		case !isDerivedComponent(c.Name) && param == ComponentParameterStructuredField:
+			if v != true {
+				return fmt.Errorf("component parameter %q of %s must be true", param, c.Name)
+			}
+		case !isDerivedComponent(c.Name) && param == ComponentParameterKey:
+			if _, ok := v.(string); !ok {
+				return fmt.Errorf("component parameter %q of %s must be a string", param, c.Name)
+			}
+		default:
+			return fmt.Errorf("unsupported component parameter %q for %s", param, c.Name)
+		}
 	}
-	if len(parts) > 1 {
-		queryName = parts[1]
+
+	return nil
+}
+
+// getHeaderComponentValue returns the header value or, with the "sf" parameter,
+// the strict serialisation of the structured field in structuredFields.
+// With the "key" parameter, it returns the Dictionary member instead
+// which fails if the field is registered as a List or an Item.
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-http-fields
+func getHeaderComponentValue(c ComponentIdentifier, msg HttpMessage, structuredFields *StructuredFieldRegistry) (string, error) {
+	key, hasKey := c.Param(ComponentParameterKey)
+	header := msg.Header().Get(c.Name)
+	if header == "" {
+		// the member of a missing Dictionary is missing as well,
+		// unlike a missing header it cannot be skipped
+		if hasKey {
+			return "", fmt.Errorf("%w: %s key %v, header not found", ErrDictionaryMemberNotFound, c.Name, key)
+		}
+		return "", fmt.Errorf("%w: %s", ErrHeaderNotFound, c.Name)
 	}
 
-	if key != "name" {
-		return "", fmt.Errorf("invalid @query-param: %s", queryName)
+	if hasKey {
+		if t, ok := structuredFields.Lookup(c.Name); ok && t != StructuredFieldDictionary {
+			return "", fmt.Errorf("%w: %s is registered as a %s", ErrInvalidDictionary, c.Name, t)
+		}
+		return GetDictionaryMemberComponentValue(c.Name, key.(string), msg)
+	}
+
+	if c.HasParam(ComponentParameterStructuredField) {
+		return structuredFields.Serialize(c.Name, msg.Header().Values(c.Name))
+	}
+
+	return header, nil
+}
+
+// GetDictionaryMemberComponentValue parses the header as a Dictionary
+// and returns the serialised value of the member with the key
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-dictionary-structured-field
+func GetDictionaryMemberComponentValue(header string, key string, msg HttpMessage) (string, error) {
+	name := strings.ToLower(header)
+
+	d, err := httpsfv.UnmarshalDictionary(msg.Header().Values(name))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrInvalidDictionary, name, err)
 	}
 
-	query := msg.Url().Query()
-	values, ok := query[key]
-	value := ""
+	member, ok := d.Get(key)
 	if !ok {
-		return "", fmt.Errorf("%s query param not found", queryName)
-	} else if len(values) == 0 {
-		value = ""
-	} else if len(values) == 1 {
-		value = values[0]
-	} else if len(values) > 1 {
-		return "", fmt.Errorf("%s query param has multiple values", queryName)
+		return "", fmt.Errorf("%w: %s key %q", ErrDictionaryMemberNotFound, name, key)
 	}
 
-	if strings.ContainsAny(value, "\r\n") {
-		return "", fmt.Errorf("%s query param has multiline value", queryName)
+	return httpsfv.Marshal(member)
+}
+
+// GetPathComponentValue returns the value of the "@path" derived component
+// which is the absolute path of the target URI without decoding percent-encoded
+// characters. An empty path is "/".
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-path
+func GetPathComponentValue(msg HttpMessage) string {
+	path := msg.Url().EscapedPath()
+	if path == "" {
+		return "/"
 	}
 
-	return value, nil
+	return path
 }
 
-func isDerivedComponent(name string) bool {
-	return strings.HasPrefix("@", name)
+// GetQueryParamComponentValue returns the value of the "@query-param" derived component
+// for the query parameter with the encoded name, i.e. the value of the name parameter
+// of the component identifier. The value is decoded and re-encoded like the name.
+// A query parameter which occurs more than once cannot be signed.
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-query-parameters
+func GetQueryParamComponentValue(name string, msg HttpMessage) (string, error) {
+	values := make([]string, 0, 1)
+	for _, pair := range strings.Split(msg.Url().RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return "", fmt.Errorf("invalid query param %s: %w", rawKey, err)
+		}
+		if EncodeQueryParam(key) != name {
+			continue
+		}
+
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return "", fmt.Errorf("invalid value of query param %s: %w", name, err)
+		}
+		values = append(values, EncodeQueryParam(value))
+	}
+
+	switch len(values) {
+	case 0:
+		return "", fmt.Errorf("%s query param not found", name)
+	case 1:
+		return values[0], nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrMultipleQueryParamValues, name)
+}
+
+// EncodeQueryParam percent-encodes a decoded query parameter name or value with the
+// application/x-www-form-urlencoded percent-encode set where spaces are encoded as %20
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#section-2.2.8-2
+func EncodeQueryParam(s string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '*', c == '-', c == '.', c == '_':
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+
+	return b.String()
 }
@@ -0,0 +1,161 @@
+package httpsig_test
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccldd/httpsig"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRequest is the request from RFC 9421 Appendix B.2
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-example-http-messages
+const TestRequest = "POST /foo?param=Value&Pet=dog HTTP/1.1\r\n" +
+	"Host: example.com\r\n" +
+	"Date: Tue, 20 Apr 2021 02:07:55 GMT\r\n" +
+	"Content-Type: application/json\r\n" +
+	"Content-Digest: sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:\r\n" +
+	"Content-Length: 18\r\n" +
+	"\r\n" +
+	`{"hello": "world"}`
+
+func mustReadRequest(t *testing.T, raw string) *http.Request {
+	t.Helper()
+
+	req, err := http.ReadRequest(bufio.NewReader(strings.NewReader(raw)))
+	assert.NoError(t, err)
+
+	return req
+}
+
+func TestGetComponentValue_Path(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *http.Request
+		expected string
+	}{
+		{
+			name:     "Appendix B.2",
+			req:      mustReadRequest(t, TestRequest),
+			expected: "/foo",
+		},
+		{
+			// https://datatracker.ietf.org/doc/html/rfc9421#section-2.2.6
+			name:     "Section 2.2.6",
+			req:      mustReadRequest(t, "GET /path?param=value HTTP/1.1\r\nHost: www.example.com\r\n\r\n"),
+			expected: "/path",
+		},
+		{
+			name:     "empty path",
+			req:      mustNewRequest(t, "https://example.com?param=value"),
+			expected: "/",
+		},
+		{
+			name:     "percent-encoding is preserved",
+			req:      mustReadRequest(t, "GET /a%20b/c%2Fd?param=value HTTP/1.1\r\nHost: www.example.com\r\n\r\n"),
+			expected: "/a%20b/c%2Fd",
+		},
+		{
+			name:     "client request",
+			req:      mustNewRequest(t, "https://example.com/foo/bar%3Fbaz?param=value"),
+			expected: "/foo/bar%3Fbaz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := httpsig.GetComponentValue(httpsig.NewComponentIdentifier(httpsig.DerivedComponentPath), &httpsig.HttpRequest{Request: tt.req})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, val)
+		})
+	}
+}
+
+func mustNewRequest(t *testing.T, url string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.NoError(t, err)
+
+	return req
+}
+
+func TestGetQueryParamComponentValue(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc9421#section-2.2.8
+	req := mustNewRequest(t, "https://example.com/parameters?var=this%20is%20a%20big%0Avalue&bar=with+plus+whitespace&fa%C3%A7ade%22%3A%20=something&empty=&flag")
+	msg := &httpsig.HttpRequest{Request: req}
+
+	tests := []struct {
+		name       string
+		identifier string
+		expected   string
+	}{
+		{"var", `"@query-param";name="var"`, "this%20is%20a%20big%0Avalue"},
+		{"bar", `"@query-param";name="bar"`, "with%20plus%20whitespace"},
+		{"façade\": ", `"@query-param";name="fa%C3%A7ade%22%3A%20"`, "something"},
+		{"empty", `"@query-param";name="empty"`, ""},
+		{"flag", `"@query-param";name="flag"`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identifier := httpsig.QueryParamComponent(tt.name)
+			assert.Equal(t, tt.identifier, identifier.String())
+
+			val, err := httpsig.GetComponentValue(identifier, msg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, val)
+		})
+	}
+
+	_, err := httpsig.GetComponentValue(httpsig.QueryParamComponent("missing"), msg)
+	assert.Error(t, err)
+
+	_, err = httpsig.GetComponentValue(httpsig.NewComponentIdentifier(httpsig.DerivedComponentQueryParam), msg)
+	assert.Error(t, err)
+
+	req = mustNewRequest(t, "https://example.com/?pet=dog&pet=cat")
+	_, err = httpsig.GetComponentValue(httpsig.QueryParamComponent("pet"), &httpsig.HttpRequest{Request: req})
+	assert.ErrorIs(t, err, httpsig.ErrMultipleQueryParamValues)
+}
+
+func TestEncodeQueryParam(t *testing.T) {
+	assert.Equal(t, "a-z.A_Z*0%7E9%2B%20%26%3D%C3%A7", httpsig.EncodeQueryParam("a-z.A_Z*0~9+ &=ç"))
+}
+
+func TestNewSignatureBaseFromRequest_QueryParam(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-selective-covered-component
+	expected := `"@authority": example.com
+"content-digest": sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:
+"@query-param";name="Pet": dog
+"@signature-params": ("@authority" "content-digest" "@query-param";name="Pet");created=1618884473;keyid="test-key-rsa-pss";tag="header-example"`
+
+	req := mustReadRequest(t, TestRequest)
+	req.URL.Host = req.Host
+	msg := &httpsig.HttpRequest{Request: req}
+
+	components := []httpsig.ComponentIdentifier{
+		httpsig.NewComponentIdentifier(httpsig.DerivedComponentAuthority),
+		httpsig.NewComponentIdentifier("Content-Digest"),
+		httpsig.QueryParamComponent("Pet"),
+	}
+	params := []httpsig.SignatureParameter{
+		httpsig.Created{Time: time.Unix(1618884473, 0)},
+		httpsig.KeyId("test-key-rsa-pss"),
+		httpsig.Tag("header-example"),
+	}
+
+	sb, err := httpsig.NewSignatureBaseFromRequest(msg, components, params)
+	assert.NoError(t, err)
+
+	base, err := sb.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, base)
+
+	_, err = httpsig.NewSignatureBaseFromRequest(msg, []httpsig.ComponentIdentifier{httpsig.QueryParamComponent("Pet"), httpsig.QueryParamComponent("Pet")}, nil)
+	assert.ErrorContains(t, err, "duplicate component")
+}
@@ -1,8 +1,8 @@
 package httpsig
 
 import (
+	"errors"
 	"fmt"
-	"reflect"
 	"time"
 
 	"github.com/dunglas/httpsfv"
@@ -15,6 +15,10 @@ const (
 	HeaderSignatureInput = "Signature-Input"
 )
 
+var (
+	ErrInvalidSignatureInput = errors.New("invalid Signature-Input")
+)
+
 type SignatureHeaderValue httpsfv.Dictionary
 
 func NewSignatureHeaderValue(sigLabel string, signature []byte) SignatureHeaderValue {
@@ -104,27 +108,34 @@ func (si SignatureInput) SigLabel() string {
 	return sigLabel
 }
 
-func (si SignatureInput) Components() []string {
-	components := make([]string, 0)
-
+// Components returns the covered components. It returns an error wrapping
+// ErrInvalidSignatureInput if the value is not an Inner List
+// of valid component identifiers.
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#section-2.1
+func (si SignatureInput) Components() ([]ComponentIdentifier, error) {
+	sigLabel := si.SigLabel()
 	d := httpsfv.Dictionary(si)
-	m, found := d.Get(si.SigLabel())
+	m, found := d.Get(sigLabel)
 	if !found {
-		return components
+		return nil, fmt.Errorf("%w: no signature found", ErrInvalidSignatureInput)
 	}
 
 	innerList, ok := m.(httpsfv.InnerList)
 	if !ok {
-		return components
+		return nil, fmt.Errorf("%w: %s is not an inner list", ErrInvalidSignatureInput, sigLabel)
 	}
 
+	components := make([]ComponentIdentifier, 0, len(innerList.Items))
 	for _, item := range innerList.Items {
-		if c, ok := item.Value.(string); ok {
-			components = append(components, c)
+		c, err := ComponentIdentifierFromItem(item)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSignatureInput, sigLabel, err)
 		}
+		components = append(components, c)
 	}
 
-	return components
+	return components, nil
 }
 
 func (si SignatureInput) SignatureParameters() []SignatureParameter {
@@ -182,9 +193,12 @@ func SignatureInputFromSignatureParams(sigLabel string, sp *SignatureParams) *Si
 		Params: httpsfv.NewParams(),
 	}
 
-	for i, item := range innerList.Items {
-		clonedVal := reflect.ValueOf(item.Value)
-		innerList.Items[i] = httpsfv.Item{Value: clonedVal}
+	for i, item := range sp.Components.Items {
+		innerList.Items[i] = httpsfv.Item{Value: item.Value, Params: httpsfv.NewParams()}
+		for _, name := range item.Params.Names() {
+			v, _ := item.Params.Get(name)
+			innerList.Items[i].Params.Add(name, v)
+		}
 	}
 
 	for _, name := range sp.Components.Params.Names() {
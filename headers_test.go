@@ -0,0 +1,31 @@
+package httpsig_test
+
+import (
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/dunglas/httpsfv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureInputFromSignatureParams(t *testing.T) {
+	sp := httpsig.SignatureParams{
+		Components: httpsfv.InnerList{
+			Items:  []httpsfv.Item{httpsfv.NewItem("@method"), httpsfv.NewItem("@authority")},
+			Params: httpsfv.NewParams(),
+		},
+	}
+	sp.Components.Params.Add(httpsig.SignatureParameterCreated, int64(1618884473))
+	sp.Components.Params.Add(httpsig.SignatureParameterKeyId, "test-key-ecc-p256")
+
+	si := httpsig.SignatureInputFromSignatureParams("sig1", &sp)
+	s, err := si.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, `sig1=("@method" "@authority");created=1618884473;keyid="test-key-ecc-p256"`, s)
+
+	// the signature input does not share items with the signature params
+	sp.Components.Items[0].Value = "@path"
+	s, err = si.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, `sig1=("@method" "@authority");created=1618884473;keyid="test-key-ecc-p256"`, s)
+}
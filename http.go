@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+
+	"github.com/dunglas/httpsfv"
 )
 
 // HttpMessage is a wrapper for http.Request or http.Response
@@ -43,59 +45,48 @@ func (hr HttpRequest) Status() int {
 }
 
 func (hr HttpRequest) SigLabels() []string {
-	labels := make([]string, 0)
-	vals := hr.Request.Header.Values(HeaderSignature)
-	for _, v := range vals {
-		if label, _, found := strings.Cut(v, "="); found {
-			labels = append(labels, label)
-		}
+	d, err := httpsfv.UnmarshalDictionary(hr.Request.Header.Values(HeaderSignature))
+	if err != nil {
+		return []string{}
 	}
 
-	return labels
+	return d.Names()
 }
 
-func (hr HttpRequest) GetSignature(sigLabel string) (sig SignatureHeaderValue, err error) {
-	var found bool
-
-	vals := hr.Request.Header.Values(HeaderSignature)
-	for _, v := range vals {
-		var label, headerValue string
-		var ok bool
-		if label, headerValue, ok = strings.Cut(v, "="); ok && label == sigLabel {
-			found = true
-			sig, _ = ParseSignatureHeaderValue(headerValue)
-		}
+func (hr HttpRequest) GetSignature(sigLabel string) (SignatureHeaderValue, error) {
+	member, err := getDictionaryMember(hr.Request.Header, HeaderSignature, sigLabel)
+	if err != nil {
+		return SignatureHeaderValue{}, err
 	}
 
-	if found {
-		err = fmt.Errorf("failed to parse signature")
-	} else {
-		err = fmt.Errorf("signature '%s' not found", sigLabel)
+	return SignatureHeaderValue(*member), nil
+}
+
+func (hr HttpRequest) GetSignatureInput(sigLabel string) (SignatureInput, error) {
+	member, err := getDictionaryMember(hr.Request.Header, HeaderSignatureInput, sigLabel)
+	if err != nil {
+		return SignatureInput{}, err
 	}
 
-	return
+	return SignatureInput(*member), nil
 }
 
-func (hr HttpRequest) GetSignatureInput(sigLabel string) (sigInput SignatureInput, err error) {
-	var found bool
-
-	vals := hr.Request.Header.Values(HeaderSignature)
-	for _, v := range vals {
-		var label, headerValue string
-		var ok bool
-		if label, headerValue, ok = strings.Cut(v, "="); ok && label == sigLabel {
-			found = true
-			sigInput, _ = ParseSignatureInput(headerValue)
-		}
+// getDictionaryMember parses the Dictionary header and
+// returns a Dictionary containing only the sigLabel member
+func getDictionaryMember(header http.Header, name string, sigLabel string) (*httpsfv.Dictionary, error) {
+	d, err := httpsfv.UnmarshalDictionary(header.Values(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", strings.ToLower(name), err)
 	}
 
-	if found {
-		err = fmt.Errorf("failed to parse signature-input")
-	} else {
-		err = fmt.Errorf("signature-input '%s' not found", sigLabel)
+	member, ok := d.Get(sigLabel)
+	if !ok {
+		return nil, fmt.Errorf("%s '%s' not found", strings.ToLower(name), sigLabel)
 	}
 
-	return
+	result := httpsfv.NewDictionary()
+	result.Add(sigLabel, member)
+	return result, nil
 }
 
 type HttpResponse struct {
@@ -0,0 +1,45 @@
+package httpsig_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpRequest_GetSignature(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	assert.NoError(t, err)
+
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-multiple-signatures
+	req.Header.Add(httpsig.HeaderSignatureInput, `sig1=("@method" "@authority");created=1618884475;keyid="test-key-ecc-p256"`)
+	req.Header.Add(httpsig.HeaderSignatureInput, `proxy_sig=("@method");created=1618884480;keyid="test-key-rsa"`)
+	req.Header.Add(httpsig.HeaderSignature, `sig1=:YWJj:, proxy_sig=:ZGVm:`)
+	msg := httpsig.HttpRequest{Request: req}
+
+	assert.Equal(t, []string{"sig1", "proxy_sig"}, msg.SigLabels())
+
+	sig, err := msg.GetSignature("proxy_sig")
+	assert.NoError(t, err)
+	b, err := sig.Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("def"), b)
+
+	sigInput, err := msg.GetSignatureInput("proxy_sig")
+	assert.NoError(t, err)
+	s, err := sigInput.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, `proxy_sig=("@method");created=1618884480;keyid="test-key-rsa"`, s)
+
+	_, err = msg.GetSignature("sig2")
+	assert.Error(t, err)
+
+	_, err = msg.GetSignatureInput("sig2")
+	assert.Error(t, err)
+
+	req.Header.Set(httpsig.HeaderSignature, `sig1=:YWJj:, (`)
+	assert.Empty(t, msg.SigLabels())
+	_, err = msg.GetSignature("sig1")
+	assert.Error(t, err)
+}
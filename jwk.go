@@ -0,0 +1,408 @@
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	JWKKeyTypeEC  = "EC"
+	JWKKeyTypeRSA = "RSA"
+	JWKKeyTypeOKP = "OKP"
+	JWKKeyTypeOct = "oct"
+)
+
+var (
+	ErrJWKNoPrivateKey = errors.New("JWK does not contain a private key")
+)
+
+// JWK is a JSON Web Key
+//
+// https://datatracker.ietf.org/doc/html/rfc7517
+type JWK struct {
+	KeyType string `json:"kty"`
+	KeyId   string `json:"kid,omitempty"`
+	Alg     string `json:"alg,omitempty"`
+	Use     string `json:"use,omitempty"`
+
+	// EC and OKP
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+
+	// RSA
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// EC, OKP and RSA private key
+	D string `json:"d,omitempty"`
+
+	// oct
+	K string `json:"k,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set
+//
+// https://datatracker.ietf.org/doc/html/rfc7517#section-5
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func ParseJWK(b []byte) (jwk JWK, err error) {
+	if err = json.Unmarshal(b, &jwk); err != nil {
+		err = fmt.Errorf("invalid JWK: %w", err)
+		return
+	}
+	if jwk.KeyType == "" {
+		err = fmt.Errorf("invalid JWK: missing kty")
+	}
+
+	return
+}
+
+func ParseJWKSet(b []byte) (set JWKSet, err error) {
+	if err = json.Unmarshal(b, &set); err != nil {
+		err = fmt.Errorf("invalid JWK Set: %w", err)
+		return
+	}
+
+	for i, jwk := range set.Keys {
+		if jwk.KeyType == "" {
+			err = fmt.Errorf("invalid JWK Set: key %d is missing kty", i)
+			return
+		}
+	}
+
+	return
+}
+
+// Key returns the key with the kid
+func (set JWKSet) Key(kid string) (JWK, bool) {
+	for _, jwk := range set.Keys {
+		if jwk.KeyId == kid {
+			return jwk, true
+		}
+	}
+
+	return JWK{}, false
+}
+
+// NewJWKFromPublicKey creates the JWK of the public key of key which is one of
+// *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey or their private keys
+func NewJWKFromPublicKey(key any) (jwk JWK, err error) {
+	if signer, ok := key.(crypto.Signer); ok {
+		key = signer.Public()
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			jwk.Curve = "P-256"
+		case elliptic.P384():
+			jwk.Curve = "P-384"
+		default:
+			err = fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+			return
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = JWKKeyTypeEC
+		jwk.X = base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size)))
+	case *rsa.PublicKey:
+		jwk.KeyType = JWKKeyTypeRSA
+		jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = JWKKeyTypeOKP
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(k)
+	default:
+		err = fmt.Errorf("unsupported key type %T", key)
+	}
+
+	return
+}
+
+// Thumbprint returns the SHA-256 JWK thumbprint of the public key, base64url encoded
+//
+// https://datatracker.ietf.org/doc/html/rfc7638
+func (jwk JWK) Thumbprint() (string, error) {
+	// only the required members, which json.Marshal sorts by name
+	var members map[string]string
+	switch jwk.KeyType {
+	case JWKKeyTypeEC:
+		members = map[string]string{"crv": jwk.Curve, "x": jwk.X, "y": jwk.Y}
+	case JWKKeyTypeRSA:
+		members = map[string]string{"e": jwk.E, "n": jwk.N}
+	case JWKKeyTypeOKP:
+		members = map[string]string{"crv": jwk.Curve, "x": jwk.X}
+	case JWKKeyTypeOct:
+		members = map[string]string{"k": jwk.K}
+	default:
+		return "", fmt.Errorf("unsupported JWK kty %q", jwk.KeyType)
+	}
+	for name, value := range members {
+		if value == "" {
+			return "", fmt.Errorf("invalid JWK: missing %s", name)
+		}
+	}
+	members["kty"] = jwk.KeyType
+
+	b, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// JWKThumbprint returns the SHA-256 JWK thumbprint of the public key of key
+// which can be used as keyid. See NewJWKFromPublicKey for the supported keys.
+//
+// https://datatracker.ietf.org/doc/html/rfc7638
+func JWKThumbprint(key any) (string, error) {
+	jwk, err := NewJWKFromPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return jwk.Thumbprint()
+}
+
+// AlgorithmName returns the RFC 9421 algorithm name of the key.
+// The JWK alg can either be a JWS algorithm or a RFC 9421 algorithm name.
+// Without alg, the algorithm is inferred from the key type where
+// RSA keys use rsa-pss-sha512.
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-json-web-signature-jws-algo
+func (jwk JWK) AlgorithmName() (string, error) {
+	if jwk.Alg != "" {
+		if name, ok := jwsAlgorithms[jwk.Alg]; ok {
+			return name, nil
+		}
+		if _, ok := rfc9421Algorithms[jwk.Alg]; ok {
+			return jwk.Alg, nil
+		}
+		return "", fmt.Errorf("unsupported JWK alg %q", jwk.Alg)
+	}
+
+	switch {
+	case jwk.KeyType == JWKKeyTypeEC && jwk.Curve == "P-256":
+		return AlgorithmEcdsaP256Sha256, nil
+	case jwk.KeyType == JWKKeyTypeEC && jwk.Curve == "P-384":
+		return AlgorithmEcdsaP384Sha384, nil
+	case jwk.KeyType == JWKKeyTypeOKP && jwk.Curve == "Ed25519":
+		return AlgorithmEd25519, nil
+	case jwk.KeyType == JWKKeyTypeRSA:
+		return AlgorithmRsaPssSha512, nil
+	case jwk.KeyType == JWKKeyTypeOct:
+		return AlgorithmHmacSha256, nil
+	}
+
+	return "", fmt.Errorf("cannot infer algorithm for JWK kty %q crv %q", jwk.KeyType, jwk.Curve)
+}
+
+// PublicKey returns the public key which is one of *ecdsa.PublicKey,
+// *rsa.PublicKey, ed25519.PublicKey or []byte for oct keys
+func (jwk JWK) PublicKey() (any, error) {
+	switch jwk.KeyType {
+	case JWKKeyTypeEC:
+		return jwk.ecdsaPublicKey()
+	case JWKKeyTypeRSA:
+		return jwk.rsaPublicKey()
+	case JWKKeyTypeOKP:
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported JWK OKP curve %q", jwk.Curve)
+		}
+		x, err := decodeJWKField("x", jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid JWK ed25519 public key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	case JWKKeyTypeOct:
+		return decodeJWKField("k", jwk.K)
+	}
+
+	return nil, fmt.Errorf("unsupported JWK kty %q", jwk.KeyType)
+}
+
+// PrivateKey returns the private key which is one of *ecdsa.PrivateKey,
+// *rsa.PrivateKey, ed25519.PrivateKey or []byte for oct keys
+func (jwk JWK) PrivateKey() (any, error) {
+	if jwk.KeyType != JWKKeyTypeOct && jwk.D == "" {
+		return nil, ErrJWKNoPrivateKey
+	}
+
+	pub, err := jwk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		return jwk.ecdsaPrivateKey(pub)
+	case *rsa.PublicKey:
+		return jwk.rsaPrivateKey(pub)
+	case ed25519.PublicKey:
+		d, err := decodeJWKField("d", jwk.D)
+		if err != nil {
+			return nil, err
+		}
+		if len(d) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid JWK ed25519 private key size %d", len(d))
+		}
+		key := ed25519.NewKeyFromSeed(d)
+		if !pub.Equal(key.Public()) {
+			return nil, fmt.Errorf("invalid JWK: d does not match x")
+		}
+		return key, nil
+	case []byte:
+		return pub, nil
+	}
+
+	return nil, fmt.Errorf("unsupported JWK kty %q", jwk.KeyType)
+}
+
+func (jwk JWK) curves() (elliptic.Curve, ecdh.Curve, error) {
+	switch jwk.Curve {
+	case "P-256":
+		return elliptic.P256(), ecdh.P256(), nil
+	case "P-384":
+		return elliptic.P384(), ecdh.P384(), nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported JWK EC curve %q", jwk.Curve)
+}
+
+func (jwk JWK) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	curve, ecdhCurve, err := jwk.curves()
+	if err != nil {
+		return nil, err
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+	x, err := decodeJWKField("x", jwk.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeJWKField("y", jwk.Y)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("invalid JWK EC coordinate size")
+	}
+
+	// ecdh checks the point is on the curve
+	uncompressed := append(append([]byte{4}, x...), y...)
+	if _, err := ecdhCurve.NewPublicKey(uncompressed); err != nil {
+		return nil, fmt.Errorf("invalid JWK EC public key: %w", err)
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+func (jwk JWK) ecdsaPrivateKey(pub *ecdsa.PublicKey) (*ecdsa.PrivateKey, error) {
+	_, ecdhCurve, err := jwk.curves()
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := decodeJWKField("d", jwk.D)
+	if err != nil {
+		return nil, err
+	}
+
+	// ecdh checks d is a valid scalar and derives the public key
+	ecdhKey, err := ecdhCurve.NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK EC private key: %w", err)
+	}
+	pubBytes := ecdhKey.PublicKey().Bytes()
+	if new(big.Int).SetBytes(pubBytes[1:1+len(d)]).Cmp(pub.X) != 0 || new(big.Int).SetBytes(pubBytes[1+len(d):]).Cmp(pub.Y) != 0 {
+		return nil, fmt.Errorf("invalid JWK: d does not match x and y")
+	}
+
+	return &ecdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(d)}, nil
+}
+
+func (jwk JWK) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeJWKField("n", jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeJWKField("e", jwk.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 2 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid JWK RSA exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+func (jwk JWK) rsaPrivateKey(pub *rsa.PublicKey) (*rsa.PrivateKey, error) {
+	fields := make(map[string]*big.Int)
+	for name, value := range map[string]string{"d": jwk.D, "p": jwk.P, "q": jwk.Q} {
+		b, err := decodeJWKField(name, value)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = new(big.Int).SetBytes(b)
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: *pub,
+		D:         fields["d"],
+		Primes:    []*big.Int{fields["p"], fields["q"]},
+	}
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid JWK RSA private key: %w", err)
+	}
+	key.Precompute()
+
+	return key, nil
+}
+
+func decodeJWKField(name string, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("invalid JWK: missing %s", name)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK %s: %w", name, err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,214 @@
+package httpsig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 9421 test keys as JWKs
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-example-keys
+const (
+	Ed25519TestJWK = `{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"kid": "test-key-ed25519",
+		"alg": "EdDSA",
+		"x": "JrQLj5P_89iXES9-vFgrIy29clF9CC_oPPsw3c5D0bs",
+		"d": "n4Ni-HpISpVObnQMW0wOhCKROaIKqKtW_2ZYb2p9KcU"
+	}`
+
+	EcdsaP256TestJWK = `{
+		"kty": "EC",
+		"crv": "P-256",
+		"kid": "test-key-ecc-p256",
+		"x": "qIVYZVLCrPZHGHjP17CTW0_-D9Lfw0EkjqF7xB4FivA",
+		"y": "Mc4nN9LTDOBhfoUeg8Ye9WedFRhnZXZJA12Qp0zZ6F0",
+		"d": "UpuF81l-kOxbjf7T4mNSv0r5tN67Gim7rnf6EFpcYDs"
+	}`
+)
+
+func TestParseJWK_Ed25519(t *testing.T) {
+	jwk, err := httpsig.ParseJWK([]byte(Ed25519TestJWK))
+	assert.NoError(t, err)
+	assert.Equal(t, "test-key-ed25519", jwk.KeyId)
+
+	name, err := jwk.AlgorithmName()
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.AlgorithmEd25519, name)
+
+	pub, err := jwk.PublicKey()
+	assert.NoError(t, err)
+	assert.IsType(t, ed25519.PublicKey{}, pub)
+
+	priv, err := jwk.PrivateKey()
+	assert.NoError(t, err)
+	assert.True(t, pub.(ed25519.PublicKey).Equal(priv.(ed25519.PrivateKey).Public()))
+}
+
+func TestParseJWK_EC(t *testing.T) {
+	jwk, err := httpsig.ParseJWK([]byte(EcdsaP256TestJWK))
+	assert.NoError(t, err)
+
+	name, err := jwk.AlgorithmName()
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.AlgorithmEcdsaP256Sha256, name)
+
+	priv, err := jwk.PrivateKey()
+	assert.NoError(t, err)
+	assert.Equal(t, 256, priv.(*ecdsa.PrivateKey).Curve.Params().BitSize)
+
+	// d does not match x and y
+	jwk.D = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAE"
+	_, err = jwk.PrivateKey()
+	assert.Error(t, err)
+
+	// not on the curve
+	jwk.X = jwk.Y
+	_, err = jwk.PublicKey()
+	assert.Error(t, err)
+}
+
+func TestParseJWK_RSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+	b, err := json.Marshal(map[string]string{
+		"kty": "RSA",
+		"alg": "PS512",
+		"n":   encode(key.N),
+		"e":   encode(big.NewInt(int64(key.E))),
+		"d":   encode(key.D),
+		"p":   encode(key.Primes[0]),
+		"q":   encode(key.Primes[1]),
+	})
+	assert.NoError(t, err)
+
+	jwk, err := httpsig.ParseJWK(b)
+	assert.NoError(t, err)
+
+	name, err := jwk.AlgorithmName()
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.AlgorithmRsaPssSha512, name)
+
+	priv, err := jwk.PrivateKey()
+	assert.NoError(t, err)
+	assert.True(t, key.Equal(priv))
+
+	jwk.D = ""
+	_, err = jwk.PrivateKey()
+	assert.ErrorIs(t, err, httpsig.ErrJWKNoPrivateKey)
+}
+
+func TestParseJWK_Oct(t *testing.T) {
+	jwk, err := httpsig.ParseJWK([]byte(`{"kty":"oct","alg":"HS256","k":"c2VjcmV0"}`))
+	assert.NoError(t, err)
+
+	name, err := jwk.AlgorithmName()
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.AlgorithmHmacSha256, name)
+
+	key, err := jwk.PrivateKey()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret"), key)
+}
+
+func TestJWK_AlgorithmName(t *testing.T) {
+	tests := []struct {
+		jwk      httpsig.JWK
+		expected string
+	}{
+		{httpsig.JWK{KeyType: "EC", Curve: "P-384"}, httpsig.AlgorithmEcdsaP384Sha384},
+		{httpsig.JWK{KeyType: "EC", Alg: "ES384"}, httpsig.AlgorithmEcdsaP384Sha384},
+		{httpsig.JWK{KeyType: "RSA"}, httpsig.AlgorithmRsaPssSha512},
+		{httpsig.JWK{KeyType: "RSA", Alg: "RS256"}, httpsig.AlgorithmRsaV15Sha256},
+		{httpsig.JWK{KeyType: "RSA", Alg: httpsig.AlgorithmRsaV15Sha256}, httpsig.AlgorithmRsaV15Sha256},
+		{httpsig.JWK{KeyType: "OKP", Curve: "Ed25519"}, httpsig.AlgorithmEd25519},
+	}
+
+	for _, tt := range tests {
+		name, err := tt.jwk.AlgorithmName()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, name)
+	}
+
+	_, err := httpsig.JWK{KeyType: "EC", Alg: "ES512"}.AlgorithmName()
+	assert.Error(t, err)
+
+	_, err = httpsig.JWK{KeyType: "OKP", Curve: "X25519"}.AlgorithmName()
+	assert.Error(t, err)
+}
+
+func TestParseJWKSet(t *testing.T) {
+	set, err := httpsig.ParseJWKSet([]byte(`{"keys":[` + Ed25519TestJWK + `,` + EcdsaP256TestJWK + `]}`))
+	assert.NoError(t, err)
+	assert.Len(t, set.Keys, 2)
+
+	jwk, ok := set.Key("test-key-ecc-p256")
+	assert.True(t, ok)
+	assert.Equal(t, "EC", jwk.KeyType)
+
+	_, ok = set.Key("unknown")
+	assert.False(t, ok)
+
+	_, err = httpsig.ParseJWKSet([]byte(`{"keys":[{"kid":"no-kty"}]}`))
+	assert.Error(t, err)
+}
+
+func TestJWK_Thumbprint(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc8037#appendix-A.3
+	jwk, err := httpsig.ParseJWK([]byte(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+	assert.NoError(t, err)
+
+	thumbprint, err := jwk.Thumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", thumbprint)
+
+	// members other than the required ones are ignored
+	jwk.KeyId = "test"
+	jwk.Alg = httpsig.JWSAlgorithmEdDSA
+	thumbprint, err = jwk.Thumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", thumbprint)
+
+	_, err = httpsig.JWK{KeyType: httpsig.JWKKeyTypeOKP, Curve: "Ed25519"}.Thumbprint()
+	assert.Error(t, err)
+}
+
+func TestJWKThumbprint(t *testing.T) {
+	for _, jwkJSON := range []string{Ed25519TestJWK, EcdsaP256TestJWK} {
+		jwk, err := httpsig.ParseJWK([]byte(jwkJSON))
+		assert.NoError(t, err)
+
+		privateKey, err := jwk.PrivateKey()
+		assert.NoError(t, err)
+		publicKey, err := jwk.PublicKey()
+		assert.NoError(t, err)
+
+		expected, err := jwk.Thumbprint()
+		assert.NoError(t, err)
+
+		thumbprint, err := httpsig.JWKThumbprint(privateKey)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, thumbprint)
+
+		thumbprint, err = httpsig.JWKThumbprint(publicKey)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, thumbprint)
+	}
+
+	_, err := httpsig.JWKThumbprint([]byte("secret"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package httpsig
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoKeyFound = errors.New("no key found")
+)
+
+// oidRsaPss is id-RSASSA-PSS which x509 does not support
+// for keys, e.g. test-key-rsa-pss in RFC 9421 Appendix B.1.2
+var oidRsaPss = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+
+// ParsePrivateKey parses a PEM or DER encoded PKCS#1, PKCS#8 or SEC1 private key.
+// PEM blocks which are not private keys, such as EC PARAMETERS, are skipped.
+//
+// The key is one of *ecdsa.PrivateKey, *rsa.PrivateKey or ed25519.PrivateKey.
+func ParsePrivateKey(b []byte) (any, error) {
+	if !isPEM(b) {
+		return parsePrivateKeyDER(b)
+	}
+
+	for block, rest := pem.Decode(b); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY":
+			if _, encrypted := block.Headers["DEK-Info"]; encrypted {
+				return nil, fmt.Errorf("encrypted PEM private keys are not supported")
+			}
+			return parsePrivateKeyDER(block.Bytes)
+		case "ENCRYPTED PRIVATE KEY":
+			return nil, fmt.Errorf("encrypted PEM private keys are not supported")
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no private key PEM block", ErrNoKeyFound)
+}
+
+// ParsePublicKey parses a PEM or DER encoded SPKI or PKCS#1 public key or
+// X.509 certificate. For certificates, the public key of the first one is returned
+// without validating it.
+//
+// The key is one of *ecdsa.PublicKey, *rsa.PublicKey or ed25519.PublicKey.
+func ParsePublicKey(b []byte) (any, error) {
+	if !isPEM(b) {
+		return parsePublicKeyDER(b)
+	}
+
+	for block, rest := pem.Decode(b); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "PUBLIC KEY", "RSA PUBLIC KEY", "CERTIFICATE":
+			return parsePublicKeyDER(block.Bytes)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no public key PEM block", ErrNoKeyFound)
+}
+
+// DefaultAlgorithmName returns the RFC 9421 algorithm name for a key
+// based on its type and curve. RSA keys use rsa-pss-sha512 and
+// []byte keys use hmac-sha256.
+func DefaultAlgorithmName(key any) (string, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		return DefaultAlgorithmName(&k.PublicKey)
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return AlgorithmEcdsaP256Sha256, nil
+		case elliptic.P384():
+			return AlgorithmEcdsaP384Sha384, nil
+		}
+		return "", fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return AlgorithmRsaPssSha512, nil
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return AlgorithmEd25519, nil
+	case []byte:
+		return AlgorithmHmacSha256, nil
+	}
+
+	return "", fmt.Errorf("unsupported key type %T", key)
+}
+
+func isPEM(b []byte) bool {
+	block, _ := pem.Decode(b)
+	return block != nil
+}
+
+func parsePrivateKeyDER(der []byte) (any, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := parsePkcs8RsaPss(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: not a PKCS#1, PKCS#8 or SEC1 private key", ErrNoKeyFound)
+}
+
+func parsePublicKeyDER(der []byte) (any, error) {
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := parseSpkiRsaPss(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return key, nil
+	}
+	if cert, err := x509.ParseCertificate(der); err == nil {
+		return cert.PublicKey, nil
+	}
+
+	return nil, fmt.Errorf("%w: not a SPKI or PKCS#1 public key or X.509 certificate", ErrNoKeyFound)
+}
+
+func parsePkcs8RsaPss(der []byte) (*rsa.PrivateKey, error) {
+	var pkcs8 struct {
+		Version    int
+		Algo       pkix.AlgorithmIdentifier
+		PrivateKey []byte
+	}
+	if _, err := asn1.Unmarshal(der, &pkcs8); err != nil {
+		return nil, err
+	}
+	if !pkcs8.Algo.Algorithm.Equal(oidRsaPss) {
+		return nil, fmt.Errorf("not an RSASSA-PSS key")
+	}
+
+	return x509.ParsePKCS1PrivateKey(pkcs8.PrivateKey)
+}
+
+func parseSpkiRsaPss(der []byte) (*rsa.PublicKey, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &spki); err != nil {
+		return nil, err
+	}
+	if !spki.Algorithm.Algorithm.Equal(oidRsaPss) {
+		return nil, fmt.Errorf("not an RSASSA-PSS key")
+	}
+
+	return x509.ParsePKCS1PublicKey(spki.PublicKey.RightAlign())
+}
@@ -0,0 +1,393 @@
+package keystore
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/ccldd/httpsig/verifier"
+)
+
+const (
+	// DefaultPollInterval is the minimum time between checking the files for changes
+	DefaultPollInterval = 10 * time.Second
+)
+
+var (
+	ErrNoPrivateKey = errors.New("no private key")
+)
+
+type Option func(*FileKeyStore)
+
+// WithPollInterval sets the minimum time between checking the files for changes
+func WithPollInterval(d time.Duration) Option {
+	return func(s *FileKeyStore) {
+		s.pollInterval = d
+	}
+}
+
+// key is a key loaded from a file
+type key struct {
+	keyId string
+	alg   string
+
+	// key is a private key, a public key or a []byte hmac secret
+	key any
+}
+
+func (k key) public() any {
+	if signer, ok := k.key.(crypto.Signer); ok {
+		return signer.Public()
+	}
+
+	return k.key
+}
+
+// FileKeyStore loads the keys in a directory and indexes them by keyid.
+// It is a verifier.KeyResolver and creates signer.KeySource with SigningKeySource.
+// It is safe for concurrent use.
+//
+// The supported files are:
+//   - .pem, .key, .crt: a PEM encoded private key, public key or certificate
+//     with the file name without extension as the keyid
+//   - .json, .jwk, .jwks: a JWK or JWK Set where the kid is the keyid.
+//     A JWK without kid uses the file name without extension.
+//
+// Other files and files starting with a dot, such as the ..data
+// symlink of Kubernetes volumes, are ignored.
+//
+// The files are checked for changes when a key is looked up at most once every poll interval.
+// The keys are replaced all at once and only if all files are valid,
+// otherwise the previously loaded keys are kept.
+type FileKeyStore struct {
+	dir          string
+	pollInterval time.Duration
+
+	// reloadMu serialises reloads
+	reloadMu sync.Mutex
+
+	mu        sync.RWMutex
+	keys      map[string]key
+	digest    []byte
+	checkedAt time.Time
+}
+
+// NewFileKeyStore creates a FileKeyStore and loads the keys in dir
+func NewFileKeyStore(dir string, opts ...Option) (*FileKeyStore, error) {
+	s := &FileKeyStore{
+		dir:          dir,
+		pollInterval: DefaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload loads the keys if the files have changed
+func (s *FileKeyStore) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	return s.reload()
+}
+
+// KeyIds returns the sorted keyids of the loaded keys
+func (s *FileKeyStore) KeyIds() []string {
+	s.poll()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keyIds := make([]string, 0, len(s.keys))
+	for keyId := range s.keys {
+		keyIds = append(keyIds, keyId)
+	}
+	slices.Sort(keyIds)
+
+	return keyIds
+}
+
+// ResolveKey creates the verifying algorithm for the key with the keyid.
+// Private keys are verified with their public key.
+func (s *FileKeyStore) ResolveKey(ctx context.Context, params verifier.KeyParameters) (verifier.VerifyingAlgorithm, error) {
+	k, err := s.key(params.KeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifier.NewVerifyingAlgorithm(k.alg, k.public())
+}
+
+// SigningKeySource returns a signer.KeySource signing with the private key with keyId.
+// If keyId is empty, the directory must contain exactly one private key which is used
+// so that keys can be rotated by replacing the file.
+func (s *FileKeyStore) SigningKeySource(keyId string) signer.KeySource {
+	return signer.KeySourceFunc(func(ctx context.Context) ([]signer.SigningKey, error) {
+		k, err := s.signingKey(keyId)
+		if err != nil {
+			return nil, err
+		}
+
+		alg, err := signer.NewSigningAlgorithm(k.alg, k.key)
+		if err != nil {
+			return nil, fmt.Errorf("keyid %q: %w", k.keyId, err)
+		}
+
+		return []signer.SigningKey{{KeyId: k.keyId, Alg: signer.NewContextSigningAlgorithm(alg)}}, nil
+	})
+}
+
+func (s *FileKeyStore) key(keyId string) (key, error) {
+	s.poll()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	k, ok := s.keys[keyId]
+	if !ok {
+		return key{}, fmt.Errorf("%w: keyid %q", verifier.ErrKeyNotFound, keyId)
+	}
+
+	return k, nil
+}
+
+func (s *FileKeyStore) signingKey(keyId string) (key, error) {
+	if keyId != "" {
+		k, err := s.key(keyId)
+		if err != nil {
+			return key{}, err
+		}
+		if !isPrivate(k.key) {
+			return key{}, fmt.Errorf("%w: keyid %q", ErrNoPrivateKey, keyId)
+		}
+		return k, nil
+	}
+
+	s.poll()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found []key
+	for _, k := range s.keys {
+		if isPrivate(k.key) {
+			found = append(found, k)
+		}
+	}
+	if len(found) != 1 {
+		return key{}, fmt.Errorf("%w: expected 1 private key in %s, found %d", ErrNoPrivateKey, s.dir, len(found))
+	}
+
+	return found[0], nil
+}
+
+// poll reloads the keys if the poll interval has passed.
+// Errors are ignored as the previously loaded keys are kept.
+func (s *FileKeyStore) poll() {
+	s.mu.RLock()
+	checkedAt := s.checkedAt
+	s.mu.RUnlock()
+	if time.Since(checkedAt) < s.pollInterval {
+		return
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	// another caller may have reloaded while waiting
+	s.mu.RLock()
+	checkedAt = s.checkedAt
+	s.mu.RUnlock()
+	if time.Since(checkedAt) < s.pollInterval {
+		return
+	}
+
+	_ = s.reload()
+}
+
+func (s *FileKeyStore) reload() error {
+	// check again after the poll interval even if loading fails
+	s.mu.Lock()
+	s.checkedAt = time.Now()
+	s.mu.Unlock()
+
+	files, err := s.readFiles()
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.New()
+	for _, f := range files {
+		fmt.Fprintf(digest, "%s\x00%d\x00", f.name, len(f.content))
+		digest.Write(f.content)
+	}
+
+	s.mu.RLock()
+	unchanged := bytes.Equal(s.digest, digest.Sum(nil))
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	keys := make(map[string]key)
+	for _, f := range files {
+		fileKeys, err := parseFile(f)
+		if err != nil {
+			return fmt.Errorf("error loading %s: %w", f.name, err)
+		}
+		for _, k := range fileKeys {
+			if _, ok := keys[k.keyId]; ok {
+				return fmt.Errorf("error loading %s: duplicate keyid %q", f.name, k.keyId)
+			}
+			keys[k.keyId] = k
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = keys
+	s.digest = digest.Sum(nil)
+
+	return nil
+}
+
+type file struct {
+	name    string
+	content []byte
+}
+
+// readFiles reads the supported files sorted by name
+func (s *FileKeyStore) readFiles() ([]file, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]file, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || !supported(name) {
+			continue
+		}
+
+		// follows symlinks
+		path := filepath.Join(s.dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file{name: name, content: content})
+	}
+
+	return files, nil
+}
+
+func supported(name string) bool {
+	switch filepath.Ext(name) {
+	case ".pem", ".key", ".crt", ".json", ".jwk", ".jwks":
+		return true
+	}
+
+	return false
+}
+
+func parseFile(f file) ([]key, error) {
+	keyId := strings.TrimSuffix(f.name, filepath.Ext(f.name))
+
+	switch filepath.Ext(f.name) {
+	case ".json", ".jwk", ".jwks":
+		return parseJWKFile(keyId, f.content)
+	}
+
+	k, err := httpsig.ParsePrivateKey(f.content)
+	if errors.Is(err, httpsig.ErrNoKeyFound) {
+		k, err = httpsig.ParsePublicKey(f.content)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	alg, err := httpsig.DefaultAlgorithmName(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return []key{{keyId: keyId, alg: alg, key: k}}, nil
+}
+
+func parseJWKFile(keyId string, b []byte) ([]key, error) {
+	set, err := httpsig.ParseJWKSet(b)
+	if err != nil || len(set.Keys) == 0 {
+		jwk, err := httpsig.ParseJWK(b)
+		if err != nil {
+			return nil, err
+		}
+		if jwk.KeyId == "" {
+			jwk.KeyId = keyId
+		}
+		set = httpsig.JWKSet{Keys: []httpsig.JWK{jwk}}
+	}
+
+	keys := make([]key, 0, len(set.Keys))
+	for i, jwk := range set.Keys {
+		if jwk.Use == "enc" {
+			continue
+		}
+		if jwk.KeyId == "" {
+			return nil, fmt.Errorf("JWK %d has no kid", i)
+		}
+
+		alg, err := jwk.AlgorithmName()
+		if err != nil {
+			return nil, fmt.Errorf("JWK %q: %w", jwk.KeyId, err)
+		}
+
+		k, err := jwk.PrivateKey()
+		if errors.Is(err, httpsig.ErrJWKNoPrivateKey) {
+			k, err = jwk.PublicKey()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("JWK %q: %w", jwk.KeyId, err)
+		}
+
+		keys = append(keys, key{keyId: jwk.KeyId, alg: alg, key: k})
+	}
+
+	return keys, nil
+}
+
+// isPrivate reports whether k can sign
+func isPrivate(k any) bool {
+	switch k.(type) {
+	case crypto.Signer, []byte:
+		return true
+	}
+
+	return false
+}
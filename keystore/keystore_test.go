@@ -0,0 +1,164 @@
+package keystore_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/keystore"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+const TestJWKSet = `{
+	"keys": [
+		{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"kid": "test-key-ed25519",
+			"x": "JrQLj5P_89iXES9-vFgrIy29clF9CC_oPPsw3c5D0bs"
+		}
+	]
+}`
+
+func writePrivateKey(t *testing.T, path string, key any) {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+}
+
+func writePublicKey(t *testing.T, path string, key any) {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+}
+
+func signRequest(t *testing.T, keys signer.KeySource) *http.Request {
+	t.Helper()
+
+	s, err := signer.NewWithKeySource(keys, "sig1", signer.WithMethod(), signer.WithCreated())
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, s.SignRequest(req))
+
+	return req
+}
+
+func TestFileKeyStore(t *testing.T) {
+	dir := t.TempDir()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	writePrivateKey(t, filepath.Join(dir, "signing-key.pem"), edKey)
+	writePublicKey(t, filepath.Join(dir, "partner-key.pem"), &ecKey.PublicKey)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "partners.jwks"), []byte(TestJWKSet), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.txt"), []byte("ignored"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden.pem"), []byte("ignored"), 0o600))
+
+	store, err := keystore.NewFileKeyStore(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"partner-key", "signing-key", "test-key-ed25519"}, store.KeyIds())
+
+	alg, err := store.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: "partner-key"})
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.AlgorithmEcdsaP256Sha256, alg.Name())
+
+	_, err = store.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: "unknown"})
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+
+	// the only private key is used without a keyid
+	req := signRequest(t, store.SigningKeySource(""))
+	assert.Contains(t, req.Header.Get(httpsig.HeaderSignatureInput), `keyid="signing-key"`)
+
+	v, err := verifier.NewWithKeyResolver(store, verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	_, err = store.SigningKeySource("partner-key").SigningKeys(context.Background())
+	assert.ErrorIs(t, err, keystore.ErrNoPrivateKey)
+}
+
+func TestFileKeyStore_Reload(t *testing.T) {
+	dir := t.TempDir()
+
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Kubernetes volumes link the files through the ..data symlink
+	// which is replaced atomically
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "..2026_10_01"), 0o700))
+	writePrivateKey(t, filepath.Join(dir, "..2026_10_01", "key-2026-10.pem"), oldKey)
+	assert.NoError(t, os.Symlink("..2026_10_01", filepath.Join(dir, "..data")))
+	assert.NoError(t, os.Symlink(filepath.Join("..data", "key-2026-10.pem"), filepath.Join(dir, "current.pem")))
+
+	store, err := keystore.NewFileKeyStore(dir, keystore.WithPollInterval(0))
+	assert.NoError(t, err)
+
+	keys := store.SigningKeySource("current")
+	req := signRequest(t, keys)
+
+	v, err := verifier.New(mustVerifyingAlgorithm(t, oldKey), verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	// an invalid file keeps the previous keys
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not a key"), 0o600))
+	assert.Error(t, store.Reload())
+	_, err = v.VerifyRequest(signRequest(t, keys))
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(filepath.Join(dir, "broken.pem")))
+
+	// swap the ..data symlink
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "..2026_11_01"), 0o700))
+	writePrivateKey(t, filepath.Join(dir, "..2026_11_01", "key-2026-10.pem"), newKey)
+	assert.NoError(t, os.Symlink("..2026_11_01", filepath.Join(dir, "..data_tmp")))
+	assert.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+	v, err = verifier.New(mustVerifyingAlgorithm(t, newKey), verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+	_, err = v.VerifyRequest(signRequest(t, keys))
+	assert.NoError(t, err)
+}
+
+func TestNewFileKeyStore_Invalid(t *testing.T) {
+	_, err := keystore.NewFileKeyStore(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.jwks"), []byte(TestJWKSet), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.jwks"), []byte(TestJWKSet), 0o600))
+	_, err = keystore.NewFileKeyStore(dir)
+	assert.ErrorContains(t, err, "duplicate keyid")
+}
+
+func mustVerifyingAlgorithm(t *testing.T, key ed25519.PrivateKey) verifier.VerifyingAlgorithm {
+	t.Helper()
+
+	alg, err := verifier.NewEd25519VerifyingAlgorithm(key.Public().(ed25519.PublicKey))
+	assert.NoError(t, err)
+
+	return alg
+}
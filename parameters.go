@@ -11,7 +11,7 @@ import (
 
 const (
 	SignatureParameterCreated = "created"
-	SignatureParameterExpires = "expired"
+	SignatureParameterExpires = "expires"
 	SignatureParameterNonce   = "nonce"
 	SignatureParameterAlg     = "alg"
 	SignatureParameterKeyId   = "keyid"
@@ -48,7 +48,7 @@ func (c Created) Value() any {
 
 func (c Created) Validate() error {
 	now := time.Now().UTC()
-	if c.Time.Add(-c.Tolerance).Before(now) {
+	if c.Time.Add(-c.Tolerance).After(now) {
 		return fmt.Errorf("signature created is not yet valid, will be valid at %s", c.Time.Local())
 	}
 
@@ -92,7 +92,7 @@ func (c Expires) Value() any {
 
 func (c Expires) Validate() error {
 	now := time.Now().UTC()
-	if c.Time.Add(c.Tolerance).After(now) {
+	if c.Time.Add(c.Tolerance).Before(now) {
 		return fmt.Errorf("signature expires is expired, expired at %s", c.Time.Local())
 	}
 
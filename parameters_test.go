@@ -0,0 +1,37 @@
+package httpsig_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ccldd/httpsig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureInput_SignatureParameters(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-signature-parameters
+	si, err := httpsig.ParseSignatureInput(`sig1=("@method");created=1618884473;expires=1618884773;keyid="test-key-rsa"`)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []httpsig.SignatureParameter{
+		httpsig.Created{Time: time.Unix(1618884473, 0)},
+		httpsig.Expires{Time: time.Unix(1618884773, 0)},
+		httpsig.KeyId("test-key-rsa"),
+	}, si.SignatureParameters())
+}
+
+func TestCreated_Validate(t *testing.T) {
+	now := time.Now()
+
+	assert.NoError(t, httpsig.Created{Time: now.Add(-time.Minute)}.Validate())
+	assert.Error(t, httpsig.Created{Time: now.Add(time.Minute)}.Validate())
+	assert.NoError(t, httpsig.Created{Time: now.Add(time.Minute), Tolerance: 2 * time.Minute}.Validate())
+}
+
+func TestExpires_Validate(t *testing.T) {
+	now := time.Now()
+
+	assert.NoError(t, httpsig.Expires{Time: now.Add(time.Minute)}.Validate())
+	assert.Error(t, httpsig.Expires{Time: now.Add(-time.Minute)}.Validate())
+	assert.NoError(t, httpsig.Expires{Time: now.Add(-time.Minute), Tolerance: 2 * time.Minute}.Validate())
+}
@@ -1,6 +1,7 @@
 package httpsig
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -8,34 +9,38 @@ import (
 )
 
 type SignatureBase struct {
-	// Keys is a slice of component names
-	// excluding @signature-params. For @query-param,
-	// there can be multiple so the key will have the
-	// query param name
+	// Components are the covered components excluding @signature-params.
+	// For @query-param, there can be multiple with different name parameters
 	//   "@query-param";name="var"
 	//   "@query-param";name="bar"
-	Keys []string
+	Components []ComponentIdentifier
 
-	// Lines is a map of component names to
-	// its string value excluding @signature-params
-	Lines map[string]string
+	// Values are the component values in the same order as Components
+	Values []string
 
 	// SignatureParams is the @signature-params which is always
 	// at the end of the signature base
 	SignatureParams SignatureParams
 }
 
+// Marshal serialises the signature base where each line starts
+// with the serialised component identifier
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-creating-the-signature-base
 func (sb *SignatureBase) Marshal() (string, error) {
 	var stringBuilder strings.Builder
 
 	// Components
-	for _, k := range sb.Keys {
-		if v, ok := sb.Lines[k]; ok {
-			stringBuilder.WriteString(k)
-			stringBuilder.WriteString(": ")
-			stringBuilder.WriteString(v)
-			stringBuilder.WriteRune('\n')
+	for i, c := range sb.Components {
+		identifier, err := c.Marshal()
+		if err != nil {
+			return "", fmt.Errorf("error marshalling signature base: %w", err)
 		}
+
+		stringBuilder.WriteString(identifier)
+		stringBuilder.WriteString(": ")
+		stringBuilder.WriteString(sb.Values[i])
+		stringBuilder.WriteRune('\n')
 	}
 
 	// Signature parameters
@@ -43,17 +48,27 @@ func (sb *SignatureBase) Marshal() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("error marshalling signature base: %w", err)
 	}
-	stringBuilder.WriteString(ComponentSignatureParams)
+	stringBuilder.WriteString(NewComponentIdentifier(ComponentSignatureParams).String())
 	stringBuilder.WriteString(": ")
 	stringBuilder.WriteString(sp)
 
 	return stringBuilder.String(), nil
 }
 
-func NewSignatureBaseFromRequest(msg HttpMessage, components []string, sigParams []SignatureParameter) (*SignatureBase, error) {
+func NewSignatureBaseFromRequest(msg HttpMessage, components []ComponentIdentifier, sigParams []SignatureParameter) (*SignatureBase, error) {
+	return NewSignatureBaseFromRequestWithRegistry(msg, components, sigParams, DefaultStructuredFieldRegistry)
+}
+
+// NewSignatureBaseFromRequestWithRegistry creates the signature base like NewSignatureBaseFromRequest
+// but looks up the structured fields in structuredFields instead of DefaultStructuredFieldRegistry
+func NewSignatureBaseFromRequestWithRegistry(msg HttpMessage, components []ComponentIdentifier, sigParams []SignatureParameter, structuredFields *StructuredFieldRegistry) (*SignatureBase, error) {
+	if structuredFields == nil {
+		structuredFields = DefaultStructuredFieldRegistry
+	}
+
 	sb := SignatureBase{
-		Keys:  make([]string, 0),
-		Lines: make(map[string]string),
+		Components: make([]ComponentIdentifier, 0, len(components)),
+		Values:     make([]string, 0, len(components)),
 		SignatureParams: SignatureParams{
 			Components: httpsfv.InnerList{
 				Items:  make([]httpsfv.Item, 0),
@@ -63,24 +78,29 @@ func NewSignatureBaseFromRequest(msg HttpMessage, components []string, sigParams
 	}
 
 	// Components
-	for _, componentName := range components {
-		if _, ok := sb.Lines[componentName]; ok {
-			return nil, fmt.Errorf("duplicate component: %s", componentName)
+	for _, c := range components {
+		if _, err := c.Marshal(); err != nil {
+			return &sb, fmt.Errorf("invalid component identifier %s: %w", c.Name, err)
+		}
+		for _, existing := range sb.Components {
+			if existing.Equal(c) {
+				return nil, fmt.Errorf("duplicate component: %s", c)
+			}
 		}
 
-		val, err := GetComponentValue(componentName, msg)
+		val, err := getComponentValue(c, msg, structuredFields)
 		if err != nil {
-			if isDerivedComponent(componentName) {
-				return &sb, err
+			// a missing header is fine
+			if errors.Is(err, ErrHeaderNotFound) {
+				continue
 			}
 
-			// a missing header is fine
-			continue
+			return &sb, err
 		}
 
-		sb.Keys = append(sb.Keys, componentName)
-		sb.Lines[componentName] = val
-		sb.SignatureParams.Components.Items = append(sb.SignatureParams.Components.Items, httpsfv.NewItem(componentName))
+		sb.Components = append(sb.Components, c)
+		sb.Values = append(sb.Values, val)
+		sb.SignatureParams.Components.Items = append(sb.SignatureParams.Components.Items, c.Item())
 	}
 
 	// Signature Parameters
@@ -0,0 +1,50 @@
+package httpsig_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ccldd/httpsig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSignatureBaseFromRequest_DerivedComponents(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/foo", nil)
+	assert.NoError(t, err)
+	msg := httpsig.HttpRequest{Request: req}
+
+	sb, err := httpsig.NewSignatureBaseFromRequest(msg, httpsig.NewComponentIdentifiers(httpsig.DerivedComponentMethod, httpsig.DerivedComponentAuthority), nil)
+	assert.NoError(t, err)
+
+	s, err := sb.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, `"@method": POST
+"@authority": example.com
+"@signature-params": ("@method" "@authority")`, s)
+
+	// derived components are not headers which can be missing
+	_, err = httpsig.NewSignatureBaseFromRequest(msg, httpsig.NewComponentIdentifiers("@unknown"), nil)
+	assert.Error(t, err)
+}
+
+func TestSignatureBase_Marshal(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-signing-a-request-using-ed2
+	req := mustReadRequest(t, TestRequest)
+	req.URL.Host = req.Host
+
+	components := httpsig.NewComponentIdentifiers("date", httpsig.DerivedComponentMethod, httpsig.DerivedComponentPath, httpsig.DerivedComponentAuthority, "content-type", "content-length")
+	sigParams := []httpsig.SignatureParameter{httpsig.Created{Time: time.Unix(1618884473, 0)}, httpsig.KeyId("test-key-ed25519")}
+	sb, err := httpsig.NewSignatureBaseFromRequest(httpsig.HttpRequest{Request: req}, components, sigParams)
+	assert.NoError(t, err)
+
+	s, err := sb.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, `"date": Tue, 20 Apr 2021 02:07:55 GMT
+"@method": POST
+"@path": /foo
+"@authority": example.com
+"content-type": application/json
+"content-length": 18
+"@signature-params": ("date" "@method" "@path" "@authority" "content-type" "content-length");created=1618884473;keyid="test-key-ed25519"`, s)
+}
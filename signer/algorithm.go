@@ -1,16 +1,19 @@
 package signer
 
 import (
+	"context"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/sha512"
 	"fmt"
-	"hash"
 
 	"github.com/ccldd/httpsig"
 	"github.com/ccldd/httpsig/verifier"
-	"golang.org/x/crypto/sha3"
 )
 
 type SigningAlgorithm interface {
@@ -18,26 +21,92 @@ type SigningAlgorithm interface {
 	Sign(b []byte) ([]byte, error)
 }
 
+// ContextSigningAlgorithm is a SigningAlgorithm which can be cancelled
+// or traced through ctx, e.g. one calling a remote KMS
+type ContextSigningAlgorithm interface {
+	httpsig.Algorithm
+	SignContext(ctx context.Context, b []byte) ([]byte, error)
+}
+
+// PublicKeyAlgorithm is implemented by signing algorithms
+// with an asymmetric key to expose the public key
+type PublicKeyAlgorithm interface {
+	Public() crypto.PublicKey
+}
+
+// publicKey returns the public key of alg or nil
+func publicKey(alg httpsig.Algorithm) crypto.PublicKey {
+	if pub, ok := alg.(PublicKeyAlgorithm); ok {
+		return pub.Public()
+	}
+
+	return nil
+}
+
+// NewContextSigningAlgorithm adapts a synchronous SigningAlgorithm into a ContextSigningAlgorithm.
+// The adapted algorithm returns the context error instead of signing
+// once ctx is done. If alg already is a ContextSigningAlgorithm or nil, it is returned as is.
+func NewContextSigningAlgorithm(alg SigningAlgorithm) ContextSigningAlgorithm {
+	if alg == nil {
+		return nil
+	}
+	if ctxAlg, ok := alg.(ContextSigningAlgorithm); ok {
+		return ctxAlg
+	}
+
+	return contextSigningAlgorithm{alg}
+}
+
+type contextSigningAlgorithm struct {
+	SigningAlgorithm
+}
+
+func (alg contextSigningAlgorithm) Public() crypto.PublicKey {
+	return publicKey(alg.SigningAlgorithm)
+}
+
+func (alg contextSigningAlgorithm) SignContext(ctx context.Context, b []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return alg.Sign(b)
+}
+
 type ecdsaSigningAlgorithm struct {
-	verifier.EcdsaVerifyingAlgorithm
 	privKey *ecdsa.PrivateKey
-	hash    hash.Hash
+
+	// hash creates a new hash.Hash per call so that
+	// the algorithm is safe for concurrent use
+	hash     crypto.Hash
+	encoding verifier.EcdsaSignatureEncoding
 }
 
-func (alg ecdsaSigningAlgorithm) Sign(b []byte) ([]byte, error) {
-	defer alg.hash.Reset()
+func (alg ecdsaSigningAlgorithm) Public() crypto.PublicKey {
+	return alg.privKey.Public()
+}
 
-	_, err := alg.hash.Write(b)
+func (alg ecdsaSigningAlgorithm) Sign(b []byte) ([]byte, error) {
+	h := alg.hash.New()
+	_, err := h.Write(b)
 	if err != nil {
 		return nil, err
 	}
 
-	digest := alg.hash.Sum(nil)
-	signed, err := alg.privKey.Sign(rand.Reader, digest[:], nil)
+	digest := h.Sum(nil)
+	if alg.encoding == verifier.EcdsaDerEncoding {
+		return ecdsa.SignASN1(rand.Reader, alg.privKey, digest)
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, alg.privKey, digest)
 	if err != nil {
 		return nil, err
 	}
 
+	signed := make([]byte, verifier.EcdsaSignatureSize(alg.privKey.Curve))
+	r.FillBytes(signed[:len(signed)/2])
+	s.FillBytes(signed[len(signed)/2:])
+
 	return signed, nil
 }
 
@@ -46,7 +115,7 @@ type EcdsaP256Sha256SigningAlgorithm struct {
 	verifier.EcdsaP256Sha256VerifyingAlgorithm
 }
 
-func NewEcdsaSha256(key *ecdsa.PrivateKey) (e EcdsaP256Sha256SigningAlgorithm, err error) {
+func NewEcdsaSha256(key *ecdsa.PrivateKey, opts ...verifier.EcdsaOption) (e EcdsaP256Sha256SigningAlgorithm, err error) {
 	if key == nil {
 		err = fmt.Errorf("key is nil")
 		return
@@ -56,9 +125,11 @@ func NewEcdsaSha256(key *ecdsa.PrivateKey) (e EcdsaP256Sha256SigningAlgorithm, e
 		return
 	}
 
+	e.EcdsaP256Sha256VerifyingAlgorithm = verifier.NewEcdsaSha256VerifyingAlgorithm(&key.PublicKey, opts...)
 	e.ecdsaSigningAlgorithm = ecdsaSigningAlgorithm{
-		privKey: key,
-		hash:    sha256.New(),
+		privKey:  key,
+		hash:     crypto.SHA256,
+		encoding: e.Encoding(),
 	}
 
 	return
@@ -69,7 +140,7 @@ type EcdsaP384Sha384SigningAlgorithm struct {
 	verifier.EcdsaP384Sha384VerifyingAlgorithm
 }
 
-func NewEcdsaP384Sha384SigningAlgorithm(key *ecdsa.PrivateKey) (e EcdsaP384Sha384SigningAlgorithm, err error) {
+func NewEcdsaP384Sha384SigningAlgorithm(key *ecdsa.PrivateKey, opts ...verifier.EcdsaOption) (e EcdsaP384Sha384SigningAlgorithm, err error) {
 	if key == nil {
 		err = fmt.Errorf("key is nil")
 		return
@@ -79,10 +150,127 @@ func NewEcdsaP384Sha384SigningAlgorithm(key *ecdsa.PrivateKey) (e EcdsaP384Sha38
 		return
 	}
 
+	e.EcdsaP384Sha384VerifyingAlgorithm = verifier.NewEcdsaSha384VerifyingAlgorithm(&key.PublicKey, opts...)
 	e.ecdsaSigningAlgorithm = ecdsaSigningAlgorithm{
-		privKey: key,
-		hash:    sha3.New384(),
+		privKey:  key,
+		hash:     crypto.SHA384,
+		encoding: e.Encoding(),
+	}
+
+	return
+}
+
+type RsaPssSha512SigningAlgorithm struct {
+	verifier.RsaPssSha512VerifyingAlgorithm
+	privKey *rsa.PrivateKey
+}
+
+func NewRsaPssSha512SigningAlgorithm(key *rsa.PrivateKey) (r RsaPssSha512SigningAlgorithm, err error) {
+	if key == nil {
+		err = fmt.Errorf("key is nil")
+		return
+	}
+
+	r.RsaPssSha512VerifyingAlgorithm, err = verifier.NewRsaPssSha512VerifyingAlgorithm(&key.PublicKey)
+	if err != nil {
+		return
+	}
+	r.privKey = key
+
+	return
+}
+
+func (alg RsaPssSha512SigningAlgorithm) Public() crypto.PublicKey {
+	return alg.privKey.Public()
+}
+
+func (alg RsaPssSha512SigningAlgorithm) Sign(b []byte) ([]byte, error) {
+	digest := sha512.Sum512(b)
+	opts := &rsa.PSSOptions{SaltLength: sha512.Size}
+	return rsa.SignPSS(rand.Reader, alg.privKey, crypto.SHA512, digest[:], opts)
+}
+
+type RsaV15Sha256SigningAlgorithm struct {
+	verifier.RsaV15Sha256VerifyingAlgorithm
+	privKey *rsa.PrivateKey
+}
+
+// NewLegacyRsaV15Sha256SigningAlgorithm opts into rsa-v1_5-sha256.
+// Prefer rsa-pss-sha512 unless the other party requires PKCS#1 v1.5.
+func NewLegacyRsaV15Sha256SigningAlgorithm(key *rsa.PrivateKey) (r RsaV15Sha256SigningAlgorithm, err error) {
+	if key == nil {
+		err = fmt.Errorf("key is nil")
+		return
+	}
+
+	r.RsaV15Sha256VerifyingAlgorithm, err = verifier.NewLegacyRsaV15Sha256VerifyingAlgorithm(&key.PublicKey)
+	if err != nil {
+		return
+	}
+	r.privKey = key
+
+	return
+}
+
+func (alg RsaV15Sha256SigningAlgorithm) Public() crypto.PublicKey {
+	return alg.privKey.Public()
+}
+
+func (alg RsaV15Sha256SigningAlgorithm) Sign(b []byte) ([]byte, error) {
+	digest := sha256.Sum256(b)
+	return rsa.SignPKCS1v15(nil, alg.privKey, crypto.SHA256, digest[:])
+}
+
+// HmacMinimumKeyBytes is the smallest shared secret, in bytes,
+// accepted by HmacSha256Algorithm
+const HmacMinimumKeyBytes = verifier.HmacMinimumKeyBytes
+
+var (
+	_ SigningAlgorithm            = HmacSha256Algorithm{}
+	_ verifier.VerifyingAlgorithm = HmacSha256Algorithm{}
+)
+
+// HmacSha256Algorithm is HMAC using SHA-256. Because the key is a shared secret,
+// it is both a SigningAlgorithm and a verifier.VerifyingAlgorithm.
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-hmac-using-sha-256
+type HmacSha256Algorithm struct {
+	verifier.HmacSha256VerifyingAlgorithm
+}
+
+func NewHmacSha256Algorithm(key []byte) (h HmacSha256Algorithm, err error) {
+	h.HmacSha256VerifyingAlgorithm, err = verifier.NewHmacSha256VerifyingAlgorithm(key)
+	return
+}
+
+func (alg HmacSha256Algorithm) Sign(b []byte) ([]byte, error) {
+	return alg.Sum(b), nil
+}
+
+type Ed25519SigningAlgorithm struct {
+	verifier.Ed25519VerifyingAlgorithm
+	privKey ed25519.PrivateKey
+}
+
+func NewEd25519SigningAlgorithm(key ed25519.PrivateKey) (e Ed25519SigningAlgorithm, err error) {
+	if len(key) != ed25519.PrivateKeySize {
+		err = fmt.Errorf("invalid ed25519 private key size %d", len(key))
+		return
 	}
 
+	e.Ed25519VerifyingAlgorithm, err = verifier.NewEd25519VerifyingAlgorithm(key.Public().(ed25519.PublicKey))
+	if err != nil {
+		return
+	}
+	e.privKey = key
+
 	return
 }
+
+func (alg Ed25519SigningAlgorithm) Public() crypto.PublicKey {
+	return alg.privKey.Public()
+}
+
+func (alg Ed25519SigningAlgorithm) Sign(b []byte) ([]byte, error) {
+	return ed25519.Sign(alg.privKey, b), nil
+}
@@ -0,0 +1,155 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/verifier"
+)
+
+// ContextCryptoSigner is a crypto.Signer which can be cancelled,
+// e.g. a cloud KMS client which makes a network call per signature
+type ContextCryptoSigner interface {
+	crypto.Signer
+	SignContext(ctx context.Context, rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+// CryptoSigningAlgorithm is a SigningAlgorithm backed by a crypto.Signer
+// so that the private key can stay in an HSM, a cloud KMS or an agent.
+// Verify uses the public key of the crypto.Signer.
+type CryptoSigningAlgorithm struct {
+	verifier.VerifyingAlgorithm
+	signer crypto.Signer
+	opts   crypto.SignerOpts
+
+	// rawEcdsa is true when the ASN.1 DER signature returned
+	// by the crypto.Signer has to be converted into r||s
+	rawEcdsa bool
+}
+
+// NewCryptoSigningAlgorithm creates a SigningAlgorithm using signer.
+// alg is the RFC 9421 algorithm name and must match the type of the public key.
+// If alg is empty, it is inferred from the public key where RSA keys use rsa-pss-sha512.
+func NewCryptoSigningAlgorithm(signer crypto.Signer, alg string) (c CryptoSigningAlgorithm, err error) {
+	if signer == nil {
+		err = fmt.Errorf("signer is nil")
+		return
+	}
+
+	c.signer = signer
+	switch pub := signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		switch {
+		case pub.Curve == elliptic.P256() && (alg == "" || alg == httpsig.AlgorithmEcdsaP256Sha256):
+			c.VerifyingAlgorithm = verifier.NewEcdsaSha256VerifyingAlgorithm(pub)
+			c.opts = crypto.SHA256
+		case pub.Curve == elliptic.P384() && (alg == "" || alg == httpsig.AlgorithmEcdsaP384Sha384):
+			c.VerifyingAlgorithm = verifier.NewEcdsaSha384VerifyingAlgorithm(pub)
+			c.opts = crypto.SHA384
+		default:
+			err = fmt.Errorf("algorithm %q is not supported for ECDSA curve %s", alg, pub.Curve.Params().Name)
+		}
+		c.rawEcdsa = true
+	case *rsa.PublicKey:
+		switch alg {
+		case "", httpsig.AlgorithmRsaPssSha512:
+			c.VerifyingAlgorithm, err = verifier.NewRsaPssSha512VerifyingAlgorithm(pub)
+			c.opts = &rsa.PSSOptions{SaltLength: sha512.Size, Hash: crypto.SHA512}
+		case httpsig.AlgorithmRsaV15Sha256:
+			c.VerifyingAlgorithm, err = verifier.NewLegacyRsaV15Sha256VerifyingAlgorithm(pub)
+			c.opts = crypto.SHA256
+		default:
+			err = fmt.Errorf("algorithm %q is not supported for RSA keys", alg)
+		}
+	case ed25519.PublicKey:
+		if alg != "" && alg != httpsig.AlgorithmEd25519 {
+			err = fmt.Errorf("algorithm %q is not supported for ed25519 keys", alg)
+			break
+		}
+		c.VerifyingAlgorithm, err = verifier.NewEd25519VerifyingAlgorithm(pub)
+		c.opts = crypto.Hash(0)
+	default:
+		err = fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	return
+}
+
+func (alg CryptoSigningAlgorithm) Public() crypto.PublicKey {
+	return alg.signer.Public()
+}
+
+func (alg CryptoSigningAlgorithm) Sign(b []byte) ([]byte, error) {
+	return alg.SignContext(context.Background(), b)
+}
+
+// SignContext passes ctx down if the crypto.Signer is a ContextCryptoSigner.
+// Otherwise, ctx is only checked before calling the crypto.Signer
+// and a call which has started runs to completion.
+func (alg CryptoSigningAlgorithm) SignContext(ctx context.Context, b []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	digest := b
+	if h := alg.opts.HashFunc(); h != 0 {
+		hash := h.New()
+		if _, err := hash.Write(b); err != nil {
+			return nil, err
+		}
+		digest = hash.Sum(nil)
+	}
+
+	var signed []byte
+	var err error
+	if ctxSigner, ok := alg.signer.(ContextCryptoSigner); ok {
+		signed, err = ctxSigner.SignContext(ctx, rand.Reader, digest, alg.opts)
+	} else {
+		signed, err = alg.signer.Sign(rand.Reader, digest, alg.opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if alg.rawEcdsa {
+		pub := alg.signer.Public().(*ecdsa.PublicKey)
+		return ecdsaDerToRaw(signed, verifier.EcdsaSignatureSize(pub.Curve))
+	}
+
+	return signed, nil
+}
+
+// ecdsaDerToRaw converts an ASN.1 DER ECDSA signature
+// into the fixed-width r||s encoding
+func ecdsaDerToRaw(der []byte, size int) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ECDSA signature: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("invalid ECDSA signature: trailing data")
+	}
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.BitLen() > size*4 || sig.S.BitLen() > size*4 {
+		return nil, fmt.Errorf("invalid ECDSA signature: r or s out of range")
+	}
+
+	raw := make([]byte, size)
+	sig.R.FillBytes(raw[:size/2])
+	sig.S.FillBytes(raw[size/2:])
+
+	return raw, nil
+}
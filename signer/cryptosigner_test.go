@@ -0,0 +1,150 @@
+package signer_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"testing"
+
+	"github.com/ccldd/httpsig/signer"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+// opaqueSigner hides the concrete private key type
+// like a KMS or HSM client would
+type opaqueSigner struct {
+	signer crypto.Signer
+	calls  int
+}
+
+func (s *opaqueSigner) Public() crypto.PublicKey {
+	return s.signer.Public()
+}
+
+func (s *opaqueSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.calls++
+	return s.signer.Sign(rand, digest, opts)
+}
+
+// contextSigner is an opaqueSigner which makes
+// a cancellable remote call per signature
+type contextSigner struct {
+	opaqueSigner
+	ctx context.Context
+}
+
+func (s *contextSigner) SignContext(ctx context.Context, rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.ctx = ctx
+	return s.Sign(rand, digest, opts)
+}
+
+func TestCryptoSigningAlgorithm_Sign(t *testing.T) {
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	rsaPss, err := verifier.NewRsaPssSha512VerifyingAlgorithm(&rsaKey.PublicKey)
+	assert.NoError(t, err)
+	rsaV15, err := verifier.NewLegacyRsaV15Sha256VerifyingAlgorithm(&rsaKey.PublicKey)
+	assert.NoError(t, err)
+	ed, err := verifier.NewEd25519VerifyingAlgorithm(edKey.Public().(ed25519.PublicKey))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		alg      string
+		key      crypto.Signer
+		verifier verifier.VerifyingAlgorithm
+	}{
+		{"ecdsa-p256-sha256", ECCP256TestKey, verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey)},
+		{"ecdsa-p384-sha384", p384Key, verifier.NewEcdsaSha384VerifyingAlgorithm(&p384Key.PublicKey)},
+		{"rsa-pss-sha512", rsaKey, rsaPss},
+		{"rsa-v1_5-sha256", rsaKey, rsaV15},
+		{"ed25519", edKey, ed},
+	}
+
+	msg := []byte(`"@signature-params": ();created=1618884473;keyid="test-key"`)
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			s := &opaqueSigner{signer: tt.key}
+			alg, err := signer.NewCryptoSigningAlgorithm(s, tt.alg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.alg, alg.Name())
+
+			sig, err := alg.Sign(msg)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, s.calls)
+			assert.True(t, tt.verifier.Verify(msg, sig))
+			assert.True(t, alg.Verify(msg, sig))
+		})
+	}
+}
+
+func TestCryptoSigningAlgorithm_SignContext(t *testing.T) {
+	s := &contextSigner{opaqueSigner: opaqueSigner{signer: ECCP256TestKey}}
+	alg, err := signer.NewCryptoSigningAlgorithm(s, "ecdsa-p256-sha256")
+	assert.NoError(t, err)
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	msg := []byte(`"@signature-params": ();created=1618884473;keyid="test-key"`)
+	sig, err := alg.SignContext(ctx, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, s.calls)
+	assert.Equal(t, ctx, s.ctx)
+	assert.True(t, alg.Verify(msg, sig))
+
+	// ctx is checked before calling the crypto.Signer
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = alg.SignContext(ctx, msg)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, s.calls)
+}
+
+func TestCryptoSigningAlgorithm_InferredAlgorithm(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	alg, err := signer.NewCryptoSigningAlgorithm(rsaKey, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "rsa-pss-sha512", alg.Name())
+
+	alg, err = signer.NewCryptoSigningAlgorithm(ECCP256TestKey, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "ecdsa-p256-sha256", alg.Name())
+}
+
+func TestCryptoSigningAlgorithm_Ed25519TestVector(t *testing.T) {
+	block, _ := pem.Decode([]byte(Ed25519TestPrivateKey))
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	assert.NoError(t, err)
+
+	alg, err := signer.NewCryptoSigningAlgorithm(&opaqueSigner{signer: key.(crypto.Signer)}, "ed25519")
+	assert.NoError(t, err)
+
+	sig, err := alg.Sign([]byte(Ed25519SignatureBase))
+	assert.NoError(t, err)
+	assert.Equal(t, mustDecodeBase64(t, Ed25519Signature), sig)
+}
+
+func TestNewCryptoSigningAlgorithm_Mismatch(t *testing.T) {
+	_, err := signer.NewCryptoSigningAlgorithm(ECCP256TestKey, "ecdsa-p384-sha384")
+	assert.Error(t, err)
+
+	_, err = signer.NewCryptoSigningAlgorithm(ECCP256TestKey, "rsa-pss-sha512")
+	assert.Error(t, err)
+
+	_, err = signer.NewCryptoSigningAlgorithm(nil, "ed25519")
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package signer
+
+import (
+	"github.com/ccldd/httpsig"
+)
+
+// NewSigningAlgorithmFromJWK creates the signing algorithm for the private key
+// in jwk. The algorithm is chosen with httpsig.JWK.AlgorithmName.
+// Use the kid of the JWK with WithKeyId.
+func NewSigningAlgorithmFromJWK(jwk httpsig.JWK) (SigningAlgorithm, error) {
+	name, err := jwk.AlgorithmName()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := jwk.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSigningAlgorithm(name, key)
+}
@@ -0,0 +1,35 @@
+package signer_test
+
+import (
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/stretchr/testify/assert"
+)
+
+const Ed25519TestJWK = `{
+	"kty": "OKP",
+	"crv": "Ed25519",
+	"kid": "test-key-ed25519",
+	"x": "JrQLj5P_89iXES9-vFgrIy29clF9CC_oPPsw3c5D0bs",
+	"d": "n4Ni-HpISpVObnQMW0wOhCKROaIKqKtW_2ZYb2p9KcU"
+}`
+
+func TestNewSigningAlgorithmFromJWK(t *testing.T) {
+	jwk, err := httpsig.ParseJWK([]byte(Ed25519TestJWK))
+	assert.NoError(t, err)
+
+	alg, err := signer.NewSigningAlgorithmFromJWK(jwk)
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.AlgorithmEd25519, alg.Name())
+
+	sig, err := alg.Sign([]byte(Ed25519SignatureBase))
+	assert.NoError(t, err)
+	assert.Equal(t, mustDecodeBase64(t, Ed25519Signature), sig)
+
+	// public keys cannot sign
+	jwk.D = ""
+	_, err = signer.NewSigningAlgorithmFromJWK(jwk)
+	assert.ErrorIs(t, err, httpsig.ErrJWKNoPrivateKey)
+}
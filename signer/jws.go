@@ -0,0 +1,67 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+
+	"github.com/ccldd/httpsig"
+)
+
+// jwsRegistry creates the algorithms behind the JWS algorithm names.
+// RS256 is included since using it is agreed on out of band.
+var jwsRegistry = newJWSRegistry()
+
+func newJWSRegistry() *httpsig.AlgorithmRegistry {
+	r := httpsig.NewAlgorithmRegistry()
+	RegisterAlgorithms(r)
+	RegisterLegacyAlgorithms(r)
+
+	return r
+}
+
+// JWSSigningAlgorithm is a SigningAlgorithm identified by a JWS
+// algorithm name instead of a RFC 9421 algorithm name.
+// Its Name is the JWS algorithm name.
+//
+// HttpMessageSigner refuses to add the alg signature parameter when signing with it.
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#section-3.3.7
+type JWSSigningAlgorithm struct {
+	SigningAlgorithm
+	name string
+}
+
+// NewJWSSigningAlgorithm creates the signing algorithm for the
+// JWS algorithm name, e.g. httpsig.JWSAlgorithmES256
+func NewJWSSigningAlgorithm(name string, key any) (*JWSSigningAlgorithm, error) {
+	rfcName, err := httpsig.AlgorithmNameFromJWS(name)
+	if err != nil {
+		return nil, err
+	}
+
+	alg, err := jwsRegistry.NewSigning(rfcName, key)
+	if err != nil {
+		return nil, err
+	}
+
+	signingAlg, ok := alg.(SigningAlgorithm)
+	if !ok {
+		return nil, fmt.Errorf("algorithm %q is not a SigningAlgorithm", rfcName)
+	}
+
+	return &JWSSigningAlgorithm{SigningAlgorithm: signingAlg, name: name}, nil
+}
+
+func (alg JWSSigningAlgorithm) Name() string {
+	return alg.name
+}
+
+func (alg JWSSigningAlgorithm) Public() crypto.PublicKey {
+	return publicKey(alg.SigningAlgorithm)
+}
+
+// SignContext passes ctx down if the underlying algorithm is a ContextSigningAlgorithm
+func (alg JWSSigningAlgorithm) SignContext(ctx context.Context, b []byte) ([]byte, error) {
+	return NewContextSigningAlgorithm(alg.SigningAlgorithm).SignContext(ctx, b)
+}
@@ -0,0 +1,62 @@
+package signer_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJWSSigningAlgorithm(t *testing.T) {
+	key, err := httpsig.ParsePrivateKey([]byte(Ed25519TestPrivateKey))
+	assert.NoError(t, err)
+
+	alg, err := signer.NewJWSSigningAlgorithm(httpsig.JWSAlgorithmEdDSA, key)
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.JWSAlgorithmEdDSA, alg.Name())
+
+	sig, err := alg.Sign([]byte(Ed25519SignatureBase))
+	assert.NoError(t, err)
+	assert.Equal(t, mustDecodeBase64(t, Ed25519Signature), sig)
+
+	_, err = signer.NewJWSSigningAlgorithm(httpsig.AlgorithmEd25519, key)
+	assert.ErrorIs(t, err, httpsig.ErrUnknownAlgorithm)
+
+	_, err = signer.NewJWSSigningAlgorithm(httpsig.JWSAlgorithmES256, key)
+	assert.Error(t, err)
+}
+
+func TestHttpMessageSigner_SignRequest_JWS(t *testing.T) {
+	alg, err := signer.NewJWSSigningAlgorithm(httpsig.JWSAlgorithmES256, ECCP256TestKey)
+	assert.NoError(t, err)
+
+	_, err = signer.New(alg, "sig1", signer.WithAlg())
+	assert.ErrorIs(t, err, httpsig.ErrJWSAlgParameter)
+
+	_, err = signer.New(alg, "sig1", signer.WithCustomAlg(httpsig.AlgorithmEcdsaP256Sha256))
+	assert.ErrorIs(t, err, httpsig.ErrJWSAlgParameter)
+
+	ecdsaAlg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(t, err)
+	_, err = signer.New(ecdsaAlg, "sig1", signer.WithCustomAlg(httpsig.JWSAlgorithmES256))
+	assert.ErrorIs(t, err, httpsig.ErrJWSAlgParameter)
+
+	s, err := signer.New(alg, "sig1", signer.WithKeyId(ECCP256TestKeyId), signer.WithMethod())
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, s.SignRequest(req))
+	assert.NotContains(t, req.Header.Get(httpsig.HeaderSignatureInput), "alg=")
+
+	verifyingAlg, err := verifier.NewJWSVerifyingAlgorithm(httpsig.JWSAlgorithmES256, &ECCP256TestKey.PublicKey)
+	assert.NoError(t, err)
+	v, err := verifier.New(verifyingAlg, verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,23 @@
+package signer
+
+import (
+	"github.com/ccldd/httpsig"
+)
+
+// ParseSigningAlgorithm parses a PEM or DER encoded private key with
+// httpsig.ParsePrivateKey and creates the signing algorithm for it.
+// The algorithm is chosen with httpsig.DefaultAlgorithmName, use
+// NewSigningAlgorithm with the parsed key for any other algorithm.
+func ParseSigningAlgorithm(b []byte) (SigningAlgorithm, error) {
+	key, err := httpsig.ParsePrivateKey(b)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := httpsig.DefaultAlgorithmName(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSigningAlgorithm(name, key)
+}
@@ -0,0 +1,22 @@
+package signer_test
+
+import (
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSigningAlgorithm(t *testing.T) {
+	alg, err := signer.ParseSigningAlgorithm([]byte(Ed25519TestPrivateKey))
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.AlgorithmEd25519, alg.Name())
+
+	sig, err := alg.Sign([]byte(Ed25519SignatureBase))
+	assert.NoError(t, err)
+	assert.Equal(t, mustDecodeBase64(t, Ed25519Signature), sig)
+
+	_, err = signer.ParseSigningAlgorithm([]byte("not a key"))
+	assert.ErrorIs(t, err, httpsig.ErrNoKeyFound)
+}
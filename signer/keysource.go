@@ -0,0 +1,103 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no signing key")
+	ErrSameSigLabel = errors.New("signing keys have the same sigLabel")
+)
+
+// SigningKey is a signing algorithm and the keyid of its key
+type SigningKey struct {
+	// KeyId is used for the keyid signature parameter if not empty
+	KeyId string
+
+	Alg ContextSigningAlgorithm
+
+	// SigLabel is the label of the signature created with the key.
+	// If empty, the sigLabel of the HttpMessageSigner is used.
+	// Each key returned by a KeySource must have a different label.
+	SigLabel string
+}
+
+// KeySource supplies the keys HttpMessageSigner signs with.
+// It is called for every message so keys can be rotated
+// without creating a new HttpMessageSigner.
+type KeySource interface {
+	// SigningKeys returns the keys to sign with. The message gets one signature
+	// per key, e.g. the old and the new key during a key rotation.
+	SigningKeys(ctx context.Context) ([]SigningKey, error)
+}
+
+// KeySourceFunc is a function implementing KeySource
+type KeySourceFunc func(ctx context.Context) ([]SigningKey, error)
+
+func (f KeySourceFunc) SigningKeys(ctx context.Context) ([]SigningKey, error) {
+	return f(ctx)
+}
+
+// staticKeySource always returns the same key
+type staticKeySource struct {
+	key SigningKey
+}
+
+func (s staticKeySource) SigningKeys(ctx context.Context) ([]SigningKey, error) {
+	return []SigningKey{s.key}, nil
+}
+
+// RotatingKeySource is a KeySource with a current key which can be rotated.
+// After rotating, the previous key keeps signing for an overlap period so that
+// verifiers have time to learn about the new key. The keys need different
+// SigLabels when using an overlap. It is safe for concurrent use.
+type RotatingKeySource struct {
+	mu           sync.RWMutex
+	current      SigningKey
+	previous     SigningKey
+	overlapUntil time.Time
+}
+
+func NewRotatingKeySource(key SigningKey) *RotatingKeySource {
+	return &RotatingKeySource{current: key}
+}
+
+// Rotate makes key the current key. The previous key is
+// also used for signing until overlap has passed.
+// It returns ErrSameSigLabel without rotating if both keys
+// would sign during the overlap with the same SigLabel.
+func (s *RotatingKeySource) Rotate(key SigningKey, overlap time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if overlap > 0 && s.current.Alg != nil && key.SigLabel == s.current.SigLabel {
+		return fmt.Errorf("%w %q, set SigLabel on the new key", ErrSameSigLabel, key.SigLabel)
+	}
+
+	s.previous = s.current
+	s.current = key
+	s.overlapUntil = time.Now().Add(overlap)
+
+	return nil
+}
+
+// SigningKeys returns the current key followed by the previous key during the overlap
+func (s *RotatingKeySource) SigningKeys(ctx context.Context) ([]SigningKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.current.Alg == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	keys := []SigningKey{s.current}
+	if s.previous.Alg != nil && time.Now().Before(s.overlapUntil) {
+		keys = append(keys, s.previous)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,134 @@
+package signer_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpMessageSigner_SignRequest_RotatingKeySource(t *testing.T) {
+	oldAlg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(t, err)
+
+	_, newKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	newAlg, err := signer.NewEd25519SigningAlgorithm(newKey)
+	assert.NoError(t, err)
+
+	keys := signer.NewRotatingKeySource(signer.SigningKey{KeyId: "old", Alg: signer.NewContextSigningAlgorithm(oldAlg)})
+	s, err := signer.NewWithKeySource(keys, "sig1", signer.WithCreated(), signer.WithMethod(), signer.WithAlg())
+	assert.NoError(t, err)
+
+	resolver := verifier.NewInMemoryKeyResolver()
+	resolver.AddKey("old", oldAlg)
+	resolver.AddKey("new", newAlg)
+
+	verify := func(req *http.Request, sigLabel string) error {
+		v, err := verifier.NewWithKeyResolver(resolver, verifier.WithSigLabel(sigLabel))
+		assert.NoError(t, err)
+		_, err = v.VerifyRequest(req)
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, s.SignRequest(req))
+	assert.Equal(t, []string{"sig1"}, httpsig.HttpRequest{Request: req}.SigLabels())
+	assert.NoError(t, verify(req, "sig1"))
+
+	// both keys sign during the overlap
+	assert.NoError(t, keys.Rotate(signer.SigningKey{KeyId: "new", Alg: signer.NewContextSigningAlgorithm(newAlg), SigLabel: "sig2"}, time.Hour))
+
+	req, err = http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, s.SignRequest(req))
+	assert.ElementsMatch(t, []string{"sig1", "sig2"}, httpsig.HttpRequest{Request: req}.SigLabels())
+	assert.NoError(t, verify(req, "sig1"))
+	assert.NoError(t, verify(req, "sig2"))
+	assert.Contains(t, req.Header.Values(httpsig.HeaderSignatureInput)[0], `keyid="new"`)
+	assert.Contains(t, req.Header.Values(httpsig.HeaderSignatureInput)[1], `keyid="old"`)
+
+	// only the new key signs after the overlap
+	assert.NoError(t, keys.Rotate(signer.SigningKey{KeyId: "new", Alg: signer.NewContextSigningAlgorithm(newAlg)}, 0))
+
+	req, err = http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, s.SignRequest(req))
+	assert.Equal(t, []string{"sig1"}, httpsig.HttpRequest{Request: req}.SigLabels())
+	assert.Contains(t, req.Header.Get(httpsig.HeaderSignatureInput), `keyid="new"`)
+	assert.NoError(t, verify(req, "sig1"))
+}
+
+func TestRotatingKeySource_Rotate_SameSigLabel(t *testing.T) {
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(t, err)
+
+	keys := signer.NewRotatingKeySource(signer.SigningKey{KeyId: "old", Alg: signer.NewContextSigningAlgorithm(alg)})
+	err = keys.Rotate(signer.SigningKey{KeyId: "new", Alg: signer.NewContextSigningAlgorithm(alg)}, time.Hour)
+	assert.ErrorIs(t, err, signer.ErrSameSigLabel)
+
+	// the old key is still the only key
+	signingKeys, err := keys.SigningKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, signingKeys, 1)
+	assert.Equal(t, "old", signingKeys[0].KeyId)
+}
+
+func TestHttpMessageSigner_SignRequest_KeySourceErrors(t *testing.T) {
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(t, err)
+
+	_, err = signer.NewWithKeySource(nil, "sig1")
+	assert.ErrorIs(t, err, signer.ErrNoSigningKey)
+
+	errSource := errors.New("key source unavailable")
+	tests := map[string]signer.KeySourceFunc{
+		"error": func(ctx context.Context) ([]signer.SigningKey, error) {
+			return nil, errSource
+		},
+		"no keys": func(ctx context.Context) ([]signer.SigningKey, error) {
+			return nil, nil
+		},
+		"no algorithm": func(ctx context.Context) ([]signer.SigningKey, error) {
+			return []signer.SigningKey{{KeyId: ECCP256TestKeyId}}, nil
+		},
+		"same sigLabel": func(ctx context.Context) ([]signer.SigningKey, error) {
+			key := signer.SigningKey{KeyId: ECCP256TestKeyId, Alg: signer.NewContextSigningAlgorithm(alg)}
+			return []signer.SigningKey{key, key}, nil
+		},
+	}
+
+	for name, keys := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := signer.NewWithKeySource(keys, "sig1")
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+			assert.NoError(t, err)
+			assert.Error(t, s.SignRequest(req))
+			assert.Empty(t, req.Header.Get(httpsig.HeaderSignature))
+			assert.Empty(t, req.Header.Get(httpsig.HeaderSignatureInput))
+		})
+	}
+}
+
+func TestHttpMessageSigner_SignRequest_KeySourceJWS(t *testing.T) {
+	alg, err := signer.NewJWSSigningAlgorithm(httpsig.JWSAlgorithmES256, ECCP256TestKey)
+	assert.NoError(t, err)
+
+	keys := signer.NewRotatingKeySource(signer.SigningKey{KeyId: ECCP256TestKeyId, Alg: alg})
+	s, err := signer.NewWithKeySource(keys, "sig1", signer.WithAlg())
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, s.SignRequest(req), httpsig.ErrJWSAlgParameter)
+}
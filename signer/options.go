@@ -1,17 +1,15 @@
 package signer
 
 import (
-	"net/http"
-
 	"github.com/ccldd/httpsig"
 )
 
 type Option func(*HttpMessageSigner)
 
-func withComponent(component string) Option {
+func withComponent(component httpsig.ComponentIdentifier) Option {
 	return func(hms *HttpMessageSigner) {
 		for i, v := range hms.components {
-			if v == component {
+			if v.Equal(component) {
 				hms.components = append(hms.components[:i], hms.components[i+1:]...)
 				break
 			}
@@ -20,48 +18,98 @@ func withComponent(component string) Option {
 	}
 }
 
+func withDerivedComponent(name string) Option {
+	return withComponent(httpsig.NewComponentIdentifier(name))
+}
+
 func WithAuthority() Option {
-	return withComponent(httpsig.DerivedComponentAuthority)
+	return withDerivedComponent(httpsig.DerivedComponentAuthority)
 }
 
 func WithMethod() Option {
-	return withComponent(httpsig.DerivedComponentMethod)
+	return withDerivedComponent(httpsig.DerivedComponentMethod)
 }
 
 func WithPath() Option {
-	return withComponent(httpsig.DerivedComponentPath)
+	return withDerivedComponent(httpsig.DerivedComponentPath)
 }
 
 func WithQuery() Option {
-	return withComponent(httpsig.DerivedComponentQuery)
+	return withDerivedComponent(httpsig.DerivedComponentQuery)
 }
 
-func WithQueryParam() Option {
-	return withComponent(httpsig.DerivedComponentQueryParam)
+// WithQueryParam adds a "@query-param" component for each query parameter.
+// The names are not percent-encoded, e.g. "var" or "façade".
+func WithQueryParam(names ...string) Option {
+	return func(hms *HttpMessageSigner) {
+		for _, name := range names {
+			withComponent(httpsig.QueryParamComponent(name))(hms)
+		}
+	}
 }
 
 func WithRequestTarget() Option {
-	return withComponent(httpsig.DerivedComponentRequestTarget)
+	return withDerivedComponent(httpsig.DerivedComponentRequestTarget)
 }
 
 func WithScheme() Option {
-	return withComponent(httpsig.DerivedComponentScheme)
+	return withDerivedComponent(httpsig.DerivedComponentScheme)
 }
 
 func WithStatus() Option {
-	return withComponent(httpsig.DerivedComponentStatus)
+	return withDerivedComponent(httpsig.DerivedComponentStatus)
 }
 
 func WithTargetUri() Option {
-	return withComponent(httpsig.DerivedComponentTargetUri)
+	return withDerivedComponent(httpsig.DerivedComponentTargetUri)
 }
 
-// WithHeaders adds headers (canonicalized) to the components
+// WithHeaders adds headers (lowercased) to the components
 func WithHeaders(headers ...string) Option {
 	return func(hms *HttpMessageSigner) {
 		for _, h := range headers {
-			canonicalized := http.CanonicalHeaderKey(h)
-			withComponent(canonicalized)(hms)
+			withComponent(httpsig.NewComponentIdentifier(h))(hms)
+		}
+	}
+}
+
+// WithStructuredFields adds headers (lowercased) with the "sf" parameter
+// so that they are strictly serialised before signing. The headers must be
+// registered in httpsig.DefaultStructuredFieldRegistry or the registry
+// set with WithStructuredFieldRegistry.
+func WithStructuredFields(headers ...string) Option {
+	return func(hms *HttpMessageSigner) {
+		for _, h := range headers {
+			withComponent(httpsig.NewComponentIdentifier(h).WithParam(httpsig.ComponentParameterStructuredField, true))(hms)
+		}
+	}
+}
+
+// WithStructuredFieldRegistry sets the registry of the structured fields
+// instead of httpsig.DefaultStructuredFieldRegistry
+func WithStructuredFieldRegistry(r *httpsig.StructuredFieldRegistry) Option {
+	return func(hms *HttpMessageSigner) {
+		hms.structuredFields = r
+	}
+}
+
+// WithDictionaryMembers adds a component with the "key" parameter for each
+// member of the Dictionary header, e.g. "example-dict";key="a".
+// Signing fails if the header is missing, is not a valid Dictionary
+// or a member is missing.
+func WithDictionaryMembers(header string, keys ...string) Option {
+	return func(hms *HttpMessageSigner) {
+		for _, key := range keys {
+			withComponent(httpsig.DictionaryMemberComponent(header, key))(hms)
+		}
+	}
+}
+
+// WithComponents adds components which can have parameters
+func WithComponents(components ...httpsig.ComponentIdentifier) Option {
+	return func(hms *HttpMessageSigner) {
+		for _, c := range components {
+			withComponent(c)(hms)
 		}
 	}
 }
@@ -101,7 +149,8 @@ func WithNonce() Option {
 }
 
 // WithAlg adds the "Alg" signature parameter and automatically gets the value
-// based on the signing algorithm and hash used
+// from the name of the signing algorithm when signing.
+// It cannot be used with a JWSSigningAlgorithm.
 func WithAlg() Option {
 	return withParameter(httpsig.Alg(""))
 }
@@ -111,7 +160,28 @@ func WithCustomAlg(alg string) Option {
 	return withParameter(httpsig.Alg(alg))
 }
 
+// WithTag adds the "Tag" signature parameter which identifies the application of the signature
+func WithTag(tag string) Option {
+	return withParameter(httpsig.Tag(tag))
+}
+
 // WithKeyId adds a specific key ID to the signature parameters
 func WithKeyId(keyId string) Option {
-	return withParameter(httpsig.KeyId(keyId))
+	return func(hms *HttpMessageSigner) {
+		hms.thumbprintKeyId = false
+		withParameter(httpsig.KeyId(keyId))(hms)
+	}
+}
+
+// WithJWKThumbprintKeyId adds the key ID signature parameter with the
+// SHA-256 JWK thumbprint of the public key of the signing algorithm.
+// The signing algorithm must implement PublicKeyAlgorithm.
+// A keyid from a SigningKey takes precedence.
+//
+// https://datatracker.ietf.org/doc/html/rfc7638
+func WithJWKThumbprintKeyId() Option {
+	return func(hms *HttpMessageSigner) {
+		hms.thumbprintKeyId = true
+		withParameter(httpsig.KeyId(""))(hms)
+	}
 }
@@ -0,0 +1,95 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"reflect"
+
+	"github.com/ccldd/httpsig"
+)
+
+func init() {
+	RegisterAlgorithms(httpsig.DefaultAlgorithmRegistry)
+}
+
+// RegisterAlgorithms registers the built-in signing algorithms in r
+// except rsa-v1_5-sha256 which has to be opted into with RegisterLegacyAlgorithms.
+//
+// The expected key types are *ecdsa.PrivateKey, *rsa.PrivateKey,
+// ed25519.PrivateKey and []byte for hmac-sha256. Any other crypto.Signer
+// is used through CryptoSigningAlgorithm.
+func RegisterAlgorithms(r *httpsig.AlgorithmRegistry) {
+	r.RegisterSigning(httpsig.AlgorithmEcdsaP256Sha256, signingFactory(httpsig.AlgorithmEcdsaP256Sha256, func(key *ecdsa.PrivateKey) (SigningAlgorithm, error) {
+		return NewEcdsaSha256(key)
+	}))
+	r.RegisterSigning(httpsig.AlgorithmEcdsaP384Sha384, signingFactory(httpsig.AlgorithmEcdsaP384Sha384, func(key *ecdsa.PrivateKey) (SigningAlgorithm, error) {
+		return NewEcdsaP384Sha384SigningAlgorithm(key)
+	}))
+	r.RegisterSigning(httpsig.AlgorithmRsaPssSha512, signingFactory(httpsig.AlgorithmRsaPssSha512, func(key *rsa.PrivateKey) (SigningAlgorithm, error) {
+		return NewRsaPssSha512SigningAlgorithm(key)
+	}))
+	r.RegisterSigning(httpsig.AlgorithmEd25519, signingFactory(httpsig.AlgorithmEd25519, func(key ed25519.PrivateKey) (SigningAlgorithm, error) {
+		return NewEd25519SigningAlgorithm(key)
+	}))
+	r.RegisterSigning(httpsig.AlgorithmHmacSha256, signingFactory(httpsig.AlgorithmHmacSha256, func(key []byte) (SigningAlgorithm, error) {
+		return NewHmacSha256Algorithm(key)
+	}))
+}
+
+// RegisterLegacyAlgorithms opts into rsa-v1_5-sha256 in r
+func RegisterLegacyAlgorithms(r *httpsig.AlgorithmRegistry) {
+	r.RegisterSigning(httpsig.AlgorithmRsaV15Sha256, signingFactory(httpsig.AlgorithmRsaV15Sha256, func(key *rsa.PrivateKey) (SigningAlgorithm, error) {
+		return NewLegacyRsaV15Sha256SigningAlgorithm(key)
+	}))
+}
+
+// NewSigningAlgorithm creates the signing algorithm registered
+// as name in httpsig.DefaultAlgorithmRegistry
+func NewSigningAlgorithm(name string, key any) (SigningAlgorithm, error) {
+	alg, err := httpsig.DefaultAlgorithmRegistry.NewSigning(name, key)
+	if err != nil {
+		return nil, err
+	}
+
+	signingAlg, ok := alg.(SigningAlgorithm)
+	if !ok {
+		return nil, fmt.Errorf("algorithm %q is not a SigningAlgorithm", name)
+	}
+
+	return signingAlg, nil
+}
+
+func signingFactory[K any](name string, newAlg func(K) (SigningAlgorithm, error)) httpsig.AlgorithmFactory {
+	return func(key any) (httpsig.Algorithm, error) {
+		if isNilKey(key) {
+			return nil, fmt.Errorf("algorithm %q: %w", name, httpsig.ErrNilKey)
+		}
+
+		switch k := key.(type) {
+		case K:
+			return newAlg(k)
+		case crypto.Signer:
+			return NewCryptoSigningAlgorithm(k, name)
+		}
+
+		return nil, fmt.Errorf("algorithm %q does not support key type %T", name, key)
+	}
+}
+
+// isNilKey reports whether key is nil or a typed nil
+// which would make the algorithm constructors panic
+func isNilKey(key any) bool {
+	if key == nil {
+		return true
+	}
+
+	switch v := reflect.ValueOf(key); v.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
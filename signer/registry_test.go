@@ -0,0 +1,72 @@
+package signer_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSigningAlgorithm(t *testing.T) {
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		key  any
+	}{
+		{httpsig.AlgorithmEcdsaP256Sha256, ECCP256TestKey},
+		{httpsig.AlgorithmEcdsaP384Sha384, p384Key},
+		{httpsig.AlgorithmRsaPssSha512, rsaKey},
+		{httpsig.AlgorithmEd25519, edKey},
+		{httpsig.AlgorithmHmacSha256, make([]byte, 32)},
+		{httpsig.AlgorithmEcdsaP256Sha256, &opaqueSigner{signer: ECCP256TestKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alg, err := signer.NewSigningAlgorithm(tt.name, tt.key)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.name, alg.Name())
+		})
+	}
+
+	_, err = signer.NewSigningAlgorithm(httpsig.AlgorithmEcdsaP384Sha384, ECCP256TestKey)
+	assert.Error(t, err)
+
+	_, err = signer.NewSigningAlgorithm(httpsig.AlgorithmHmacSha256, "secret")
+	assert.Error(t, err)
+
+	// typed nil keys
+	_, err = signer.NewSigningAlgorithm(httpsig.AlgorithmEcdsaP256Sha256, (*ecdsa.PrivateKey)(nil))
+	assert.ErrorIs(t, err, httpsig.ErrNilKey)
+	_, err = signer.NewSigningAlgorithm(httpsig.AlgorithmRsaPssSha512, (*rsa.PrivateKey)(nil))
+	assert.ErrorIs(t, err, httpsig.ErrNilKey)
+	_, err = signer.NewSigningAlgorithm(httpsig.AlgorithmEd25519, nil)
+	assert.ErrorIs(t, err, httpsig.ErrNilKey)
+}
+
+func TestRegisterLegacyAlgorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	_, err = signer.NewSigningAlgorithm(httpsig.AlgorithmRsaV15Sha256, rsaKey)
+	assert.ErrorIs(t, err, httpsig.ErrUnknownAlgorithm)
+
+	r := httpsig.NewAlgorithmRegistry()
+	signer.RegisterLegacyAlgorithms(r)
+
+	alg, err := r.NewSigning(httpsig.AlgorithmRsaV15Sha256, rsaKey)
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.AlgorithmRsaV15Sha256, alg.Name())
+}
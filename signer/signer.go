@@ -1,6 +1,7 @@
 package signer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -18,14 +19,26 @@ type Signer interface {
 	SignRequest(req *http.Request) error
 }
 
-// HttpMessageSigner implements Signer
-// using SigningAlgorithm
+// ContextSigner is a Signer which passes a context
+// down to the signing algorithm
+type ContextSigner interface {
+	Signer
+	SignRequestContext(ctx context.Context, req *http.Request) error
+}
+
+// HttpMessageSigner implements Signer and ContextSigner
+// using the keys from a KeySource
 type HttpMessageSigner struct {
-	components          []string
+	components          []httpsig.ComponentIdentifier
 	signatureParameters []httpsig.SignatureParameter
 
-	alg      SigningAlgorithm
+	keys     KeySource
 	sigLabel string
+
+	structuredFields *httpsig.StructuredFieldRegistry
+
+	// thumbprintKeyId is true when the keyid is the JWK thumbprint of the signing key
+	thumbprintKeyId bool
 }
 
 func (hms HttpMessageSigner) validate() error {
@@ -34,12 +47,29 @@ func (hms HttpMessageSigner) validate() error {
 	if hms.sigLabel == "" {
 		errs = append(errs, ErrNoSigLabel)
 	}
+	if hms.keys == nil {
+		errs = append(errs, ErrNoSigningKey)
+	}
+	for _, p := range hms.signatureParameters {
+		if alg, ok := p.(httpsig.Alg); ok && httpsig.IsJWSAlgorithm(string(alg)) {
+			errs = append(errs, httpsig.ErrJWSAlgParameter)
+		}
+	}
+	if static, ok := hms.keys.(staticKeySource); ok {
+		if static.key.Alg == nil {
+			errs = append(errs, ErrNoSigningKey)
+		} else if _, err := hms.resolveSignatureParameters(static.key); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
 	return errors.Join(errs...)
 }
 
-func new(opts ...Option) *HttpMessageSigner {
-	signer := &HttpMessageSigner{}
+func newHttpMessageSigner(opts ...Option) *HttpMessageSigner {
+	signer := &HttpMessageSigner{
+		structuredFields: httpsig.DefaultStructuredFieldRegistry,
+	}
 	for _, opt := range opts {
 		opt(signer)
 	}
@@ -48,8 +78,20 @@ func new(opts ...Option) *HttpMessageSigner {
 }
 
 func New(alg SigningAlgorithm, sigLabel string, opts ...Option) (*HttpMessageSigner, error) {
-	s := new(opts...)
-	s.alg = alg
+	return NewWithContext(NewContextSigningAlgorithm(alg), sigLabel, opts...)
+}
+
+// NewWithContext creates a HttpMessageSigner using a ContextSigningAlgorithm
+func NewWithContext(alg ContextSigningAlgorithm, sigLabel string, opts ...Option) (*HttpMessageSigner, error) {
+	return NewWithKeySource(staticKeySource{key: SigningKey{Alg: alg}}, sigLabel, opts...)
+}
+
+// NewWithKeySource creates a HttpMessageSigner which gets the keys
+// from keys every time it signs. The keyid of a SigningKey
+// takes precedence over WithKeyId.
+func NewWithKeySource(keys KeySource, sigLabel string, opts ...Option) (*HttpMessageSigner, error) {
+	s := newHttpMessageSigner(opts...)
+	s.keys = keys
 	s.sigLabel = sigLabel
 
 	if err := s.validate(); err != nil {
@@ -58,44 +100,140 @@ func New(alg SigningAlgorithm, sigLabel string, opts ...Option) (*HttpMessageSig
 	return s, nil
 }
 
+// SignRequest signs req using req.Context()
 func (s *HttpMessageSigner) SignRequest(req *http.Request) error {
+	return s.SignRequestContext(req.Context(), req)
+}
+
+// SignRequestContext signs req with every key from the KeySource.
+// The headers are only added once all signatures are created.
+func (s *HttpMessageSigner) SignRequestContext(ctx context.Context, req *http.Request) error {
 	msg := &httpsig.HttpRequest{Request: req}
-	errors.Join()
+
+	keys, err := s.keys.SigningKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("HttpMessageSigner.SignRequest error getting signing keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("HttpMessageSigner.SignRequest: %w", ErrNoSigningKey)
+	}
+
+	signatures := make([]string, 0, len(keys))
+	signatureInputs := make([]string, 0, len(keys))
+	sigLabels := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		sigLabel := key.SigLabel
+		if key.Alg == nil {
+			return fmt.Errorf("HttpMessageSigner.SignRequest: %w for keyid %q", ErrNoSigningKey, key.KeyId)
+		}
+		if sigLabel == "" {
+			sigLabel = s.sigLabel
+		}
+		if _, ok := sigLabels[sigLabel]; ok {
+			return fmt.Errorf("HttpMessageSigner.SignRequest: %w %q", ErrSameSigLabel, sigLabel)
+		}
+		sigLabels[sigLabel] = struct{}{}
+
+		signature, signatureInput, err := s.sign(ctx, msg, key, sigLabel)
+		if err != nil {
+			return err
+		}
+		signatures = append(signatures, signature)
+		signatureInputs = append(signatureInputs, signatureInput)
+	}
+
+	for i := range signatures {
+		msg.Header().Add(httpsig.HeaderSignature, signatures[i])
+		msg.Header().Add(httpsig.HeaderSignatureInput, signatureInputs[i])
+	}
+
+	return nil
+}
+
+// sign creates the Signature and Signature-Input header values for key
+func (s *HttpMessageSigner) sign(ctx context.Context, msg *httpsig.HttpRequest, key SigningKey, sigLabel string) (signature string, signatureInput string, err error) {
+	params, err := s.resolveSignatureParameters(key)
+	if err != nil {
+		err = fmt.Errorf("HttpMessageSigner.SignRequest: %w", err)
+		return
+	}
 
 	// Form Signature Base
-	sb, err := httpsig.NewSignatureBaseFromRequest(msg, s.components, s.signatureParameters)
+	sb, err := httpsig.NewSignatureBaseFromRequestWithRegistry(msg, s.components, params, s.structuredFields)
 	if err != nil {
-		return fmt.Errorf("HttpMessageSigner.SignRequest error creating signature base: %w", err)
+		err = fmt.Errorf("HttpMessageSigner.SignRequest error creating signature base: %w", err)
+		return
 	}
 
 	sbString, err := sb.Marshal()
 	if err != nil {
-		return fmt.Errorf("HttpMessageSigner.SignRequest error marshalling signature base: %w", err)
+		err = fmt.Errorf("HttpMessageSigner.SignRequest error marshalling signature base: %w", err)
+		return
 	}
 
 	// Calculate Signature
 	sbBytes := []byte(sbString)
-	signatureBytes, err := s.alg.Sign(sbBytes)
+	signatureBytes, err := key.Alg.SignContext(ctx, sbBytes)
 	if err != nil {
-		return fmt.Errorf("HttpMessageSigner.SignRequest error generating signature: %w", err)
+		err = fmt.Errorf("HttpMessageSigner.SignRequest error generating signature: %w", err)
+		return
 	}
 
-	// Add signature to headers
-	signatureHeaderValue := httpsig.NewSignatureHeaderValue(s.sigLabel, signatureBytes)
-	signatureHeaderValueStr, err := signatureHeaderValue.Marshal()
+	signatureHeaderValue := httpsig.NewSignatureHeaderValue(sigLabel, signatureBytes)
+	signature, err = signatureHeaderValue.Marshal()
 	if err != nil {
-		return fmt.Errorf("HttpMessageSigner.SignRequest error adding %s: %w", strconv.Quote(httpsig.HeaderSignature), err)
+		err = fmt.Errorf("HttpMessageSigner.SignRequest error adding %s: %w", strconv.Quote(httpsig.HeaderSignature), err)
+		return
 	}
-	msg.Header().Add(httpsig.HeaderSignature, signatureHeaderValueStr)
 
-	// Add Signature-Input to headers
-	signatureInput := httpsig.SignatureInputFromSignatureParams(s.sigLabel, &sb.SignatureParams)
-	signatureInputStr, err := signatureInput.Marshal()
+	signatureInputValue := httpsig.SignatureInputFromSignatureParams(sigLabel, &sb.SignatureParams)
+	signatureInput, err = signatureInputValue.Marshal()
 	if err != nil {
-		return fmt.Errorf("HttpMessageSigner.SignRequest error adding %s: %w", strconv.Quote(httpsig.HeaderSignatureInput), err)
+		err = fmt.Errorf("HttpMessageSigner.SignRequest error adding %s: %w", strconv.Quote(httpsig.HeaderSignatureInput), err)
 	}
 
-	msg.Header().Add(httpsig.HeaderSignatureInput, signatureInputStr)
+	return
+}
 
-	return nil
+// resolveSignatureParameters fills in the value of the alg signature parameter
+// from the signing algorithm and the keyid from the signing key or its JWK thumbprint.
+// The alg signature parameter cannot be used with JWS algorithms.
+func (s *HttpMessageSigner) resolveSignatureParameters(key SigningKey) ([]httpsig.SignatureParameter, error) {
+	if s.thumbprintKeyId && key.KeyId == "" {
+		pub := publicKey(key.Alg)
+		if pub == nil {
+			return nil, fmt.Errorf("algorithm %q has no public key for the JWK thumbprint", key.Alg.Name())
+		}
+
+		thumbprint, err := httpsig.JWKThumbprint(pub)
+		if err != nil {
+			return nil, fmt.Errorf("error creating JWK thumbprint: %w", err)
+		}
+		key.KeyId = thumbprint
+	}
+
+	params := make([]httpsig.SignatureParameter, 0, len(s.signatureParameters)+1)
+	hasKeyId := false
+	for _, p := range s.signatureParameters {
+		switch pp := p.(type) {
+		case httpsig.Alg:
+			if httpsig.IsJWSAlgorithm(key.Alg.Name()) {
+				return nil, httpsig.ErrJWSAlgParameter
+			}
+			if pp == "" {
+				p = httpsig.Alg(key.Alg.Name())
+			}
+		case httpsig.KeyId:
+			hasKeyId = true
+			if key.KeyId != "" {
+				p = httpsig.KeyId(key.KeyId)
+			}
+		}
+		params = append(params, p)
+	}
+	if !hasKeyId && key.KeyId != "" {
+		params = append(params, httpsig.KeyId(key.KeyId))
+	}
+
+	return params, nil
 }
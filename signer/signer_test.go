@@ -1,6 +1,7 @@
 package signer_test
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/base64"
@@ -49,3 +50,191 @@ func TestHttpMessageSigner_SignRequest(t *testing.T) {
 
 	assert.NotEmpty(req.Header.Get(httpsig.HeaderSignature))
 }
+
+type ctxKey struct{}
+
+// remoteSigningAlgorithm records the context it was called with
+type remoteSigningAlgorithm struct {
+	signer.SigningAlgorithm
+	ctx context.Context
+}
+
+func (alg *remoteSigningAlgorithm) SignContext(ctx context.Context, b []byte) ([]byte, error) {
+	alg.ctx = ctx
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return alg.Sign(b)
+}
+
+func TestHttpMessageSigner_SignRequestContext(t *testing.T) {
+	assert := assert.New(t)
+
+	ecdsaAlg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(err)
+	alg := &remoteSigningAlgorithm{SigningAlgorithm: ecdsaAlg}
+
+	s, err := signer.NewWithContext(alg, "sig1", signer.WithKeyId(ECCP256TestKeyId))
+	assert.NoError(err)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "trace")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	assert.NoError(err)
+
+	assert.NoError(s.SignRequest(req))
+	assert.Equal("trace", alg.ctx.Value(ctxKey{}))
+	assert.NotEmpty(req.Header.Get(httpsig.HeaderSignature))
+}
+
+func TestHttpMessageSigner_SignRequestContext_Cancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(err)
+
+	s, err := signer.New(alg, "sig1")
+	assert.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(err)
+
+	err = s.SignRequestContext(ctx, req)
+	assert.ErrorIs(err, context.Canceled)
+	assert.Empty(req.Header.Get(httpsig.HeaderSignature))
+}
+
+func TestHttpMessageSigner_SignRequest_WithAlg(t *testing.T) {
+	assert := assert.New(t)
+
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(err)
+
+	s, err := signer.New(alg, "sig1", signer.WithAlg(), signer.WithKeyId(ECCP256TestKeyId))
+	assert.NoError(err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(err)
+	assert.NoError(s.SignRequest(req))
+
+	sigInput, err := httpsig.HttpRequest{Request: req}.GetSignatureInput("sig1")
+	assert.NoError(err)
+	assert.Contains(sigInput.SignatureParameters(), httpsig.Alg(httpsig.AlgorithmEcdsaP256Sha256))
+	assert.Contains(req.Header.Get(httpsig.HeaderSignatureInput), `alg="ecdsa-p256-sha256"`)
+}
+
+func TestNew_NoAlgorithm(t *testing.T) {
+	_, err := signer.New(nil, "sig1", signer.WithAlg())
+	assert.ErrorIs(t, err, signer.ErrNoSigningKey)
+
+	_, err = signer.NewWithContext(nil, "sig1", signer.WithAlg(), signer.WithJWKThumbprintKeyId())
+	assert.ErrorIs(t, err, signer.ErrNoSigningKey)
+}
+
+func TestHttpMessageSigner_SignRequest_WithJWKThumbprintKeyId(t *testing.T) {
+	assert := assert.New(t)
+
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(err)
+
+	thumbprint, err := httpsig.JWKThumbprint(ECCP256TestKey)
+	assert.NoError(err)
+
+	s, err := signer.New(alg, "sig1", signer.WithJWKThumbprintKeyId())
+	assert.NoError(err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(err)
+	assert.NoError(s.SignRequest(req))
+
+	sigInput, err := httpsig.HttpRequest{Request: req}.GetSignatureInput("sig1")
+	assert.NoError(err)
+	assert.Contains(sigInput.SignatureParameters(), httpsig.KeyId(thumbprint))
+
+	// the shared secret of hmac-sha256 has no public key
+	hmac, err := signer.NewHmacSha256Algorithm([]byte(HmacTestSharedSecret))
+	assert.NoError(err)
+	_, err = signer.New(hmac, "sig1", signer.WithJWKThumbprintKeyId())
+	assert.Error(err)
+}
+
+func TestHttpMessageSigner_SignRequest_WithQueryParam(t *testing.T) {
+	assert := assert.New(t)
+
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(err)
+
+	s, err := signer.New(alg, "sig1", signer.WithMethod(), signer.WithQueryParam("Pet", "façade"), signer.WithQueryParam("Pet"))
+	assert.NoError(err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/?Pet=dog&fa%C3%A7ade=front", nil)
+	assert.NoError(err)
+	assert.NoError(s.SignRequest(req))
+
+	assert.Equal(`sig1=("@method" "@query-param";name="fa%C3%A7ade" "@query-param";name="Pet")`, req.Header.Get(httpsig.HeaderSignatureInput))
+
+	req, err = http.NewRequest(http.MethodGet, "https://example.com/?Pet=dog", nil)
+	assert.NoError(err)
+	assert.Error(s.SignRequest(req))
+}
+
+func TestHttpMessageSigner_SignRequest_WithStructuredFields(t *testing.T) {
+	assert := assert.New(t)
+
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(err)
+
+	s, err := signer.New(alg, "sig1", signer.WithStructuredFields(httpsig.HeaderContentDigest))
+	assert.NoError(err)
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/", nil)
+	assert.NoError(err)
+	req.Header.Set(httpsig.HeaderContentDigest, "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:")
+	assert.NoError(s.SignRequest(req))
+
+	assert.Equal(`sig1=("content-digest";sf)`, req.Header.Get(httpsig.HeaderSignatureInput))
+
+	// the header is not a valid Dictionary
+	req, err = http.NewRequest(http.MethodPost, "https://example.com/", nil)
+	assert.NoError(err)
+	req.Header.Set(httpsig.HeaderContentDigest, ":X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:")
+	assert.Error(s.SignRequest(req))
+
+	// the header is not in the registry
+	s, err = signer.New(alg, "sig1", signer.WithStructuredFields(httpsig.HeaderContentDigest), signer.WithStructuredFieldRegistry(httpsig.NewStructuredFieldRegistry()))
+	assert.NoError(err)
+	req, err = http.NewRequest(http.MethodPost, "https://example.com/", nil)
+	assert.NoError(err)
+	req.Header.Set(httpsig.HeaderContentDigest, "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:")
+	assert.ErrorIs(s.SignRequest(req), httpsig.ErrUnknownStructuredField)
+}
+
+func TestHttpMessageSigner_SignRequest_WithDictionaryMembers(t *testing.T) {
+	assert := assert.New(t)
+
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(err)
+
+	s, err := signer.New(alg, "sig1", signer.WithDictionaryMembers("Cache-Control-Ext", "a", "b"))
+	assert.NoError(err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	assert.NoError(err)
+	req.Header.Set("Cache-Control-Ext", "a=1, b=2, c=3")
+	assert.NoError(s.SignRequest(req))
+
+	assert.Equal(`sig1=("cache-control-ext";key="a" "cache-control-ext";key="b")`, req.Header.Get(httpsig.HeaderSignatureInput))
+
+	req.Header.Del(httpsig.HeaderSignatureInput)
+	req.Header.Set("Cache-Control-Ext", "a=1")
+	assert.ErrorIs(s.SignRequest(req), httpsig.ErrDictionaryMemberNotFound)
+
+	req.Header.Set("Cache-Control-Ext", "a=(")
+	assert.ErrorIs(s.SignRequest(req), httpsig.ErrInvalidDictionary)
+
+	req.Header.Del("Cache-Control-Ext")
+	assert.ErrorIs(s.SignRequest(req), httpsig.ErrDictionaryMemberNotFound)
+}
@@ -0,0 +1,135 @@
+package httpsig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/dunglas/httpsfv"
+)
+
+var (
+	ErrUnknownStructuredField = errors.New("unknown structured field")
+)
+
+// StructuredFieldType is the top-level type of a structured field
+//
+// https://datatracker.ietf.org/doc/html/rfc8941#name-defining-new-structured-fie
+type StructuredFieldType int
+
+const (
+	StructuredFieldList StructuredFieldType = iota + 1
+	StructuredFieldDictionary
+	StructuredFieldItem
+)
+
+func (t StructuredFieldType) String() string {
+	switch t {
+	case StructuredFieldList:
+		return "List"
+	case StructuredFieldDictionary:
+		return "Dictionary"
+	case StructuredFieldItem:
+		return "Item"
+	}
+
+	return fmt.Sprintf("StructuredFieldType(%d)", int(t))
+}
+
+// StructuredFieldRegistry maps field names to their structured field type
+// so that they can be strictly serialised with the "sf" component parameter.
+// It is safe for concurrent use.
+type StructuredFieldRegistry struct {
+	mu     sync.RWMutex
+	fields map[string]StructuredFieldType
+}
+
+// DefaultStructuredFieldRegistry is the registry used when none is specified.
+// It contains the structured fields used by HTTP Message Signatures and
+// other registered structured fields.
+var DefaultStructuredFieldRegistry = NewStructuredFieldRegistry()
+
+func init() {
+	for name, t := range map[string]StructuredFieldType{
+		"accept-ch":                    StructuredFieldList,
+		"accept-signature":             StructuredFieldDictionary,
+		"cache-status":                 StructuredFieldList,
+		"cdn-cache-control":            StructuredFieldDictionary,
+		"client-cert":                  StructuredFieldItem,
+		"client-cert-chain":            StructuredFieldList,
+		"content-digest":               StructuredFieldDictionary,
+		"cross-origin-embedder-policy": StructuredFieldItem,
+		"cross-origin-opener-policy":   StructuredFieldItem,
+		"origin-agent-cluster":         StructuredFieldItem,
+		"priority":                     StructuredFieldDictionary,
+		"proxy-status":                 StructuredFieldList,
+		"repr-digest":                  StructuredFieldDictionary,
+		"signature":                    StructuredFieldDictionary,
+		"signature-input":              StructuredFieldDictionary,
+		"want-content-digest":          StructuredFieldDictionary,
+		"want-repr-digest":             StructuredFieldDictionary,
+	} {
+		DefaultStructuredFieldRegistry.Register(name, t)
+	}
+}
+
+func NewStructuredFieldRegistry() *StructuredFieldRegistry {
+	return &StructuredFieldRegistry{
+		fields: make(map[string]StructuredFieldType),
+	}
+}
+
+// Register registers the type of the field, replacing any type
+// already registered. The name is case-insensitive.
+func (r *StructuredFieldRegistry) Register(name string, t StructuredFieldType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fields[strings.ToLower(name)] = t
+}
+
+// Lookup returns the type of the field
+func (r *StructuredFieldRegistry) Lookup(name string) (StructuredFieldType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.fields[strings.ToLower(name)]
+	return t, ok
+}
+
+// Serialize parses the field values as the registered type of the field
+// and returns the strict serialisation
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-strict-serialization-of-htt
+func (r *StructuredFieldRegistry) Serialize(name string, values []string) (string, error) {
+	t, ok := r.Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownStructuredField, strings.ToLower(name))
+	}
+
+	var (
+		sf  httpsfv.StructuredFieldValue
+		err error
+	)
+	switch t {
+	case StructuredFieldList:
+		sf, err = httpsfv.UnmarshalList(values)
+	case StructuredFieldDictionary:
+		sf, err = httpsfv.UnmarshalDictionary(values)
+	case StructuredFieldItem:
+		sf, err = httpsfv.UnmarshalItem(values)
+	default:
+		return "", fmt.Errorf("%w: %s has invalid type %s", ErrUnknownStructuredField, strings.ToLower(name), t)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s as %s: %w", strings.ToLower(name), t, err)
+	}
+
+	return httpsfv.Marshal(sf)
+}
+
+// RegisterStructuredField registers the type of a field in DefaultStructuredFieldRegistry
+func RegisterStructuredField(name string, t StructuredFieldType) {
+	DefaultStructuredFieldRegistry.Register(name, t)
+}
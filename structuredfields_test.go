@@ -0,0 +1,199 @@
+package httpsig_test
+
+import (
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuredFieldRegistry_Serialize(t *testing.T) {
+	r := httpsig.NewStructuredFieldRegistry()
+	r.Register("Example-Dict", httpsig.StructuredFieldDictionary)
+	r.Register("example-list", httpsig.StructuredFieldList)
+	r.Register("example-item", httpsig.StructuredFieldItem)
+
+	tests := []struct {
+		name     string
+		values   []string
+		expected string
+	}{
+		{
+			// https://datatracker.ietf.org/doc/html/rfc9421#section-2.1.1
+			name:     "example-dict",
+			values:   []string{"a=1,    b=2;x=1;y=2,   c=(a   b   c)"},
+			expected: "a=1, b=2;x=1;y=2, c=(a b c)",
+		},
+		{
+			name:     "example-dict",
+			values:   []string{"a=1", "b=?1,  c=\"x\""},
+			expected: `a=1, b, c="x"`,
+		},
+		{
+			name:     "example-list",
+			values:   []string{"a,   (b  c);x=1", "d"},
+			expected: "a, (b c);x=1, d",
+		},
+		{
+			name:     "example-item",
+			values:   []string{"   ?1;a=1"},
+			expected: "?1;a=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			s, err := r.Serialize(tt.name, tt.values)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, s)
+		})
+	}
+
+	_, err := r.Serialize("unknown", []string{"a=1"})
+	assert.ErrorIs(t, err, httpsig.ErrUnknownStructuredField)
+
+	_, err = r.Serialize("example-dict", []string{"a=("})
+	assert.Error(t, err)
+}
+
+func TestDefaultStructuredFieldRegistry(t *testing.T) {
+	for _, name := range []string{httpsig.HeaderSignature, httpsig.HeaderSignatureInput, httpsig.HeaderContentDigest} {
+		typ, ok := httpsig.DefaultStructuredFieldRegistry.Lookup(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, httpsig.StructuredFieldDictionary, typ, name)
+	}
+}
+
+func TestSignatureBase_StructuredField(t *testing.T) {
+	r := httpsig.NewStructuredFieldRegistry()
+	r.Register("Example-Dict", httpsig.StructuredFieldDictionary)
+
+	req := mustNewRequest(t, "https://example.com/")
+	req.Header.Set("Example-Dict", "a=1,    b=2;x=1;y=2,   c=(a   b   c)")
+
+	components := []httpsig.ComponentIdentifier{
+		httpsig.NewComponentIdentifier("Example-Dict"),
+		httpsig.NewComponentIdentifier("Example-Dict").WithParam(httpsig.ComponentParameterStructuredField, true),
+	}
+	sb, err := httpsig.NewSignatureBaseFromRequestWithRegistry(httpsig.HttpRequest{Request: req}, components, nil, r)
+	assert.NoError(t, err)
+
+	s, err := sb.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, `"example-dict": a=1,    b=2;x=1;y=2,   c=(a   b   c)
+"example-dict";sf: a=1, b=2;x=1;y=2, c=(a b c)
+"@signature-params": ("example-dict" "example-dict";sf)`, s)
+
+	// the field is only registered in r
+	_, err = httpsig.NewSignatureBaseFromRequest(httpsig.HttpRequest{Request: req}, components[1:], nil)
+	assert.ErrorIs(t, err, httpsig.ErrUnknownStructuredField)
+
+	// the header is not a valid Dictionary
+	req.Header.Set("Example-Dict", "a=(")
+	_, err = httpsig.NewSignatureBaseFromRequestWithRegistry(httpsig.HttpRequest{Request: req}, components[1:], nil, r)
+	assert.Error(t, err)
+
+	// sf is only supported for registered headers
+	req.Header.Set("Unknown", "a=1")
+	_, err = httpsig.NewSignatureBaseFromRequest(httpsig.HttpRequest{Request: req}, []httpsig.ComponentIdentifier{
+		httpsig.NewComponentIdentifier("Unknown").WithParam(httpsig.ComponentParameterStructuredField, true),
+	}, nil)
+	assert.ErrorIs(t, err, httpsig.ErrUnknownStructuredField)
+
+	// sf is not supported for derived components
+	_, err = httpsig.NewSignatureBaseFromRequest(httpsig.HttpRequest{Request: req}, []httpsig.ComponentIdentifier{
+		httpsig.NewComponentIdentifier(httpsig.DerivedComponentMethod).WithParam(httpsig.ComponentParameterStructuredField, true),
+	}, nil)
+	assert.Error(t, err)
+}
+
+func TestGetDictionaryMemberComponentValue(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc9421#section-2.1.2
+	req := mustNewRequest(t, "https://example.com/")
+	req.Header.Set("Example-Dict", "a=1, b=2;x=1;y=2, c=(a   b    c), d")
+	msg := httpsig.HttpRequest{Request: req}
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"a", "1"},
+		{"d", "?1"},
+		{"b", "2;x=1;y=2"},
+		{"c", "(a b c)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			val, err := httpsig.GetComponentValue(httpsig.DictionaryMemberComponent("Example-Dict", tt.key), msg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, val)
+		})
+	}
+
+	_, err := httpsig.GetComponentValue(httpsig.DictionaryMemberComponent("Example-Dict", "e"), msg)
+	assert.ErrorIs(t, err, httpsig.ErrDictionaryMemberNotFound)
+
+	req.Header.Set("Example-Header", "(a b")
+	_, err = httpsig.GetComponentValue(httpsig.DictionaryMemberComponent("Example-Header", "a"), msg)
+	assert.ErrorIs(t, err, httpsig.ErrInvalidDictionary)
+
+	_, err = httpsig.GetComponentValue(httpsig.NewComponentIdentifier("Example-Dict").WithParam(httpsig.ComponentParameterKey, 1), msg)
+	assert.Error(t, err)
+}
+
+func TestSignatureBase_DictionaryMember(t *testing.T) {
+	req := mustNewRequest(t, "https://example.com/")
+	req.Header.Set("Example-Dict", "a=1, b=2;x=1;y=2, c=(a   b    c), d")
+
+	components := []httpsig.ComponentIdentifier{
+		httpsig.DictionaryMemberComponent("Example-Dict", "a"),
+		httpsig.DictionaryMemberComponent("Example-Dict", "c"),
+	}
+	sb, err := httpsig.NewSignatureBaseFromRequest(httpsig.HttpRequest{Request: req}, components, nil)
+	assert.NoError(t, err)
+
+	s, err := sb.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, `"example-dict";key="a": 1
+"example-dict";key="c": (a b c)
+"@signature-params": ("example-dict";key="a" "example-dict";key="c")`, s)
+
+	// a missing member cannot be signed
+	req.Header.Set("Example-Dict", "a=1")
+	_, err = httpsig.NewSignatureBaseFromRequest(httpsig.HttpRequest{Request: req}, components, nil)
+	assert.ErrorIs(t, err, httpsig.ErrDictionaryMemberNotFound)
+
+	// unlike a missing header, a member of a missing header is not skipped
+	req.Header.Del("Example-Dict")
+	_, err = httpsig.NewSignatureBaseFromRequest(httpsig.HttpRequest{Request: req}, components, nil)
+	assert.ErrorIs(t, err, httpsig.ErrDictionaryMemberNotFound)
+}
+
+func TestSignatureBase_DictionaryMember_StructuredFieldType(t *testing.T) {
+	r := httpsig.NewStructuredFieldRegistry()
+	r.Register("Example-Dict", httpsig.StructuredFieldDictionary)
+	r.Register("Example-List", httpsig.StructuredFieldList)
+	r.Register("Example-Item", httpsig.StructuredFieldItem)
+
+	req := mustNewRequest(t, "https://example.com/")
+	req.Header.Set("Example-Dict", "a=1")
+	req.Header.Set("Example-List", "a=1")
+	req.Header.Set("Example-Item", "a=1")
+	msg := httpsig.HttpRequest{Request: req}
+
+	_, err := httpsig.NewSignatureBaseFromRequestWithRegistry(msg, []httpsig.ComponentIdentifier{httpsig.DictionaryMemberComponent("Example-Dict", "a")}, nil, r)
+	assert.NoError(t, err)
+
+	// key is only supported for Dictionary fields
+	for _, name := range []string{"Example-List", "Example-Item"} {
+		_, err = httpsig.NewSignatureBaseFromRequestWithRegistry(msg, []httpsig.ComponentIdentifier{httpsig.DictionaryMemberComponent(name, "a")}, nil, r)
+		assert.ErrorIs(t, err, httpsig.ErrInvalidDictionary, name)
+	}
+
+	// the member is already strictly serialised
+	_, err = httpsig.NewSignatureBaseFromRequestWithRegistry(msg, []httpsig.ComponentIdentifier{
+		httpsig.DictionaryMemberComponent("Example-Dict", "a").WithParam(httpsig.ComponentParameterStructuredField, true),
+	}, nil, r)
+	assert.ErrorContains(t, err, "cannot be combined")
+}
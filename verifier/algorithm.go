@@ -1,12 +1,20 @@
 package verifier
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
 	"crypto/sha256"
-	"hash"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
 
 	"github.com/ccldd/httpsig"
-	"golang.org/x/crypto/sha3"
+	_ "golang.org/x/crypto/sha3"
 )
 
 type VerifyingAlgorithm interface {
@@ -14,51 +22,268 @@ type VerifyingAlgorithm interface {
 	Verify(b []byte, signature []byte) bool
 }
 
+// EcdsaSignatureEncoding is how the r and s values of an
+// ECDSA signature are encoded
+type EcdsaSignatureEncoding int
+
+const (
+	// EcdsaRawEncoding is the fixed-width big-endian concatenation of r and s
+	// required by RFC 9421
+	//
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-ecdsa-using-curve-p-256-dss
+	EcdsaRawEncoding EcdsaSignatureEncoding = iota
+
+	// EcdsaDerEncoding is the ASN.1 DER encoding that earlier versions of this
+	// library produced. It is not interoperable with other RFC 9421
+	// implementations and is only meant for migrating existing deployments.
+	EcdsaDerEncoding
+)
+
+type EcdsaOption func(*EcdsaVerifyingAlgorithm)
+
+// WithEcdsaDerEncoding opts into ASN.1 DER encoded signatures
+// instead of the RFC 9421 r||s encoding
+func WithEcdsaDerEncoding() EcdsaOption {
+	return func(alg *EcdsaVerifyingAlgorithm) {
+		alg.encoding = EcdsaDerEncoding
+	}
+}
+
+// WithLegacySha3Fallback makes ecdsa-p384-sha384 also accept signatures
+// over a SHA3-384 digest, which earlier versions of this library produced.
+// It is meant for a migration window only and has no effect on ecdsa-p256-sha256.
+func WithLegacySha3Fallback() EcdsaOption {
+	return func(alg *EcdsaVerifyingAlgorithm) {
+		alg.legacyHash = crypto.SHA3_384
+	}
+}
+
 type EcdsaVerifyingAlgorithm struct {
 	pubKey *ecdsa.PublicKey
-	hash   hash.Hash
+
+	// hash and legacyHash create a new hash.Hash per call
+	// so that the algorithm is safe for concurrent use
+	hash       crypto.Hash
+	legacyHash crypto.Hash
+	encoding   EcdsaSignatureEncoding
+}
+
+func newEcdsaVerifyingAlgorithm(key *ecdsa.PublicKey, h crypto.Hash, opts ...EcdsaOption) EcdsaVerifyingAlgorithm {
+	alg := EcdsaVerifyingAlgorithm{
+		pubKey: key,
+		hash:   h,
+	}
+	for _, opt := range opts {
+		opt(&alg)
+	}
+
+	return alg
+}
+
+// Encoding returns the signature encoding
+func (alg EcdsaVerifyingAlgorithm) Encoding() EcdsaSignatureEncoding {
+	return alg.encoding
 }
 
 func (alg EcdsaVerifyingAlgorithm) Verify(b []byte, signature []byte) bool {
-	defer alg.hash.Reset()
-	if _, err := alg.hash.Write(b); err != nil {
+	if alg.verifyWithHash(alg.hash, b, signature) {
+		return true
+	}
+
+	return alg.legacyHash != 0 && alg.verifyWithHash(alg.legacyHash, b, signature)
+}
+
+func (alg EcdsaVerifyingAlgorithm) verifyWithHash(hash crypto.Hash, b []byte, signature []byte) bool {
+	h := hash.New()
+	if _, err := h.Write(b); err != nil {
+		return false
+	}
+
+	digest := h.Sum(nil)
+	if alg.encoding == EcdsaDerEncoding {
+		return ecdsa.VerifyASN1(alg.pubKey, digest, signature)
+	}
+
+	size := EcdsaSignatureSize(alg.pubKey.Curve)
+	if len(signature) != size {
 		return false
 	}
 
-	hash := alg.hash.Sum(nil)
-	return ecdsa.VerifyASN1(alg.pubKey, hash, signature)
+	r := new(big.Int).SetBytes(signature[:size/2])
+	s := new(big.Int).SetBytes(signature[size/2:])
+	return ecdsa.Verify(alg.pubKey, digest, r, s)
+}
+
+// EcdsaSignatureSize returns the size in bytes of a r||s encoded
+// signature, which is twice the size of the curve order
+func EcdsaSignatureSize(curve elliptic.Curve) int {
+	return 2 * ((curve.Params().N.BitLen() + 7) / 8)
 }
 
 type EcdsaP256Sha256VerifyingAlgorithm struct {
 	EcdsaVerifyingAlgorithm
 }
 
-func NewEcdsaSha256VerifyingAlgorithm(key *ecdsa.PublicKey) EcdsaP256Sha256VerifyingAlgorithm {
+func NewEcdsaSha256VerifyingAlgorithm(key *ecdsa.PublicKey, opts ...EcdsaOption) EcdsaP256Sha256VerifyingAlgorithm {
+	alg := newEcdsaVerifyingAlgorithm(key, crypto.SHA256, opts...)
+	alg.legacyHash = 0 // there was never a SHA3 variant of P256
+
 	return EcdsaP256Sha256VerifyingAlgorithm{
-		EcdsaVerifyingAlgorithm: EcdsaVerifyingAlgorithm{
-			pubKey: key,
-			hash:   sha256.New(),
-		},
+		EcdsaVerifyingAlgorithm: alg,
 	}
 }
 
 func (alg EcdsaP256Sha256VerifyingAlgorithm) Name() string {
-	return "ecdsa-p256-sha256"
+	return httpsig.AlgorithmEcdsaP256Sha256
 }
 
+// EcdsaP384Sha384VerifyingAlgorithm is ECDSA using curve P-384 and SHA-384
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-ecdsa-using-curve-p-384-dss
 type EcdsaP384Sha384VerifyingAlgorithm struct {
 	EcdsaVerifyingAlgorithm
 }
 
-func NewEcdsaSha384VerifyingAlgorithm(key *ecdsa.PublicKey) EcdsaP384Sha384VerifyingAlgorithm {
+func NewEcdsaSha384VerifyingAlgorithm(key *ecdsa.PublicKey, opts ...EcdsaOption) EcdsaP384Sha384VerifyingAlgorithm {
 	return EcdsaP384Sha384VerifyingAlgorithm{
-		EcdsaVerifyingAlgorithm: EcdsaVerifyingAlgorithm{
-			pubKey: key,
-			hash:   sha3.New384(),
-		},
+		EcdsaVerifyingAlgorithm: newEcdsaVerifyingAlgorithm(key, crypto.SHA384, opts...),
 	}
 }
 
 func (alg EcdsaP384Sha384VerifyingAlgorithm) Name() string {
-	return "ecdsa-p384-sha384"
+	return httpsig.AlgorithmEcdsaP384Sha384
+}
+
+// RsaMinimumModulusBits is the smallest RSA modulus size, in bits,
+// accepted by the RSA algorithms
+const RsaMinimumModulusBits = 2048
+
+func validateRsaPublicKey(key *rsa.PublicKey) error {
+	if key == nil {
+		return fmt.Errorf("key is nil")
+	}
+	if bits := key.N.BitLen(); bits < RsaMinimumModulusBits {
+		return fmt.Errorf("key size %d is smaller than the minimum %d bits", bits, RsaMinimumModulusBits)
+	}
+
+	return nil
+}
+
+// RsaPssSha512VerifyingAlgorithm is RSASSA-PSS using SHA-512
+// with a salt length of 64 bytes
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-rsassa-pss-using-sha-512
+type RsaPssSha512VerifyingAlgorithm struct {
+	pubKey *rsa.PublicKey
+}
+
+func NewRsaPssSha512VerifyingAlgorithm(key *rsa.PublicKey) (alg RsaPssSha512VerifyingAlgorithm, err error) {
+	if err = validateRsaPublicKey(key); err != nil {
+		return
+	}
+
+	alg.pubKey = key
+	return
+}
+
+func (alg RsaPssSha512VerifyingAlgorithm) Verify(b []byte, signature []byte) bool {
+	digest := sha512.Sum512(b)
+	opts := &rsa.PSSOptions{SaltLength: sha512.Size}
+	return rsa.VerifyPSS(alg.pubKey, crypto.SHA512, digest[:], signature, opts) == nil
+}
+
+func (alg RsaPssSha512VerifyingAlgorithm) Name() string {
+	return httpsig.AlgorithmRsaPssSha512
+}
+
+// RsaV15Sha256VerifyingAlgorithm is RSASSA-PKCS1-v1_5 using SHA-256.
+// It is the weakest of the registered algorithms and is only meant
+// for interoperating with legacy systems which cannot use anything else.
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-rsassa-pkcs1-v1_5-using-sha
+type RsaV15Sha256VerifyingAlgorithm struct {
+	pubKey *rsa.PublicKey
+}
+
+// NewLegacyRsaV15Sha256VerifyingAlgorithm opts into rsa-v1_5-sha256.
+// Prefer rsa-pss-sha512 unless the other party requires PKCS#1 v1.5.
+func NewLegacyRsaV15Sha256VerifyingAlgorithm(key *rsa.PublicKey) (alg RsaV15Sha256VerifyingAlgorithm, err error) {
+	if err = validateRsaPublicKey(key); err != nil {
+		return
+	}
+
+	alg.pubKey = key
+	return
+}
+
+func (alg RsaV15Sha256VerifyingAlgorithm) Verify(b []byte, signature []byte) bool {
+	digest := sha256.Sum256(b)
+	return rsa.VerifyPKCS1v15(alg.pubKey, crypto.SHA256, digest[:], signature) == nil
+}
+
+func (alg RsaV15Sha256VerifyingAlgorithm) Name() string {
+	return httpsig.AlgorithmRsaV15Sha256
+}
+
+// Ed25519VerifyingAlgorithm is EdDSA using curve edwards25519
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-eddsa-using-curve-edwards25
+type Ed25519VerifyingAlgorithm struct {
+	pubKey ed25519.PublicKey
+}
+
+func NewEd25519VerifyingAlgorithm(key ed25519.PublicKey) (alg Ed25519VerifyingAlgorithm, err error) {
+	if len(key) != ed25519.PublicKeySize {
+		err = fmt.Errorf("invalid ed25519 public key size %d", len(key))
+		return
+	}
+
+	alg.pubKey = key
+	return
+}
+
+func (alg Ed25519VerifyingAlgorithm) Verify(b []byte, signature []byte) bool {
+	return ed25519.Verify(alg.pubKey, b, signature)
+}
+
+func (alg Ed25519VerifyingAlgorithm) Name() string {
+	return httpsig.AlgorithmEd25519
+}
+
+// HmacMinimumKeyBytes is the smallest shared secret, in bytes,
+// accepted by HmacSha256VerifyingAlgorithm which is the size of the SHA-256 output
+const HmacMinimumKeyBytes = sha256.Size
+
+// HmacSha256VerifyingAlgorithm is HMAC using SHA-256
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-hmac-using-sha-256
+type HmacSha256VerifyingAlgorithm struct {
+	key []byte
+}
+
+func NewHmacSha256VerifyingAlgorithm(key []byte) (alg HmacSha256VerifyingAlgorithm, err error) {
+	if len(key) < HmacMinimumKeyBytes {
+		err = fmt.Errorf("key size %d is smaller than the minimum %d bytes", len(key), HmacMinimumKeyBytes)
+		return
+	}
+
+	alg.key = bytes.Clone(key)
+	return
+}
+
+// Sum returns the HMAC of b. Because the key is a shared secret,
+// this is also the signature.
+func (alg HmacSha256VerifyingAlgorithm) Sum(b []byte) []byte {
+	mac := hmac.New(sha256.New, alg.key)
+	mac.Write(b)
+	return mac.Sum(nil)
+}
+
+// Verify compares the signature in constant time
+func (alg HmacSha256VerifyingAlgorithm) Verify(b []byte, signature []byte) bool {
+	return hmac.Equal(alg.Sum(b), signature)
+}
+
+func (alg HmacSha256VerifyingAlgorithm) Name() string {
+	return httpsig.AlgorithmHmacSha256
 }
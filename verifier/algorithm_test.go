@@ -0,0 +1,286 @@
+package verifier_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"sync"
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+// Test keys and signatures from RFC 9421 Appendix B
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#name-example-keys
+const (
+	RsaPssTestPublicKey = `-----BEGIN PUBLIC KEY-----
+MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAr4tmm3r20Wd/PbqvP1s2
++QEtvpuRaV8Yq40gjUR8y2Rjxa6dpG2GXHbPfvMs8ct+Lh1GH45x28Rw3Ry53mm+
+oAXjyQ86OnDkZ5N8lYbggD4O3w6M6pAvLkhk95AndTrifbIFPNU8PPMO7OyrFAHq
+gDsznjPFmTOtCEcN2Z1FpWgchwuYLPL+Wokqltd11nqqzi+bJ9cvSKADYdUAAN5W
+Utzdpiy6LbTgSxP7ociU4Tn0g5I6aDZJ7A8Lzo0KSyZYoA485mqcO0GVAdVw9lq4
+aOT9v6d+nb4bnNkQVklLQ3fVAvJm+xdDOp9LCNCN48V2pnDOkFV6+U9nV5oyc6XI
+2wIDAQAB
+-----END PUBLIC KEY-----`
+
+	EcdsaP256TestPublicKey = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEqIVYZVLCrPZHGHjP17CTW0/+D9Lf
+w0EkjqF7xB4FivAxzic30tMM4GF+hR6Dxh71Z50VGGdldkkDXZCnTNnoXQ==
+-----END PUBLIC KEY-----`
+
+	Ed25519TestPublicKey = `-----BEGIN PUBLIC KEY-----
+MCowBQYDK2VwAyEAJrQLj5P/89iXES9+vFgrIy29clF9CC/oPPsw3c5D0bs=
+-----END PUBLIC KEY-----`
+
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-minimal-signature-using-rsa
+	RsaPssMinimalSignatureBase = `"@signature-params": ();created=1618884473;keyid="test-key-rsa-pss";nonce="b3k2pp5k7z-50gnwp.yemd"`
+	RsaPssMinimalSignature     = `d2pmTvmbncD3xQm8E9ZV2828BjQWGgiwAaw5bAkgibUopemLJcWDy/lkbbHAve4cRAtx31Iq786U7it++wgGxbtRxf8Udx7zFZsckzXaJMkA7ChG52eSkFxykJeNqsrWH5S+oxNFlD4dzVuwe8DhTSja8xxbR/Z2cOGdCbzR72rgFWhzx2VjBqJzsPLMIQKhO4DGezXehhWwE56YCE+O6c0mKZsfxVrogUvA4HELjVKWmAvtl6UnCh8jYzuVG5WSb/QEVPnP5TmcAnLH1g+s++v6d4s8m0gCw1fV5/SITLq9mhho8K3+7EPYTU8IU1bLhdxO5Nyt8C8ssinQ98Xw9Q==`
+
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-selective-covered-component
+	RsaPssSelectiveSignatureBase = `"@authority": example.com
+"content-digest": sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:
+"@query-param";name="Pet": dog
+"@signature-params": ("@authority" "content-digest" "@query-param";name="Pet");created=1618884473;keyid="test-key-rsa-pss";tag="header-example"`
+	RsaPssSelectiveSignature = `LjbtqUbfmvjj5C5kr1Ugj4PmLYvx9wVjZvD9GsTT4F7GrcQEdJzgI9qHxICagShLRiLMlAJjtq6N4CDfKtjvuJyE5qH7KT8UCMkSowOB4+ECxCmT8rtAmj/0PIXxi0A0nxKyB09RNrCQibbUjsLS/2YyFYXEu4TRJQzRw1rLEuEfY17SARYhpTlaqwZVtR8NV7+4UKkjqpcAoFqWFQh62s7Cl+H2fjBSpqfZUJcsIk4N6wiKYd4je2U/lankenQ99PZfB4jY3I5rSV2DSBVkSFsURIjYErOs0tFTQosMTAoxk//0RoKUqiYY8Bh0aaUEb0rQl3/XaVe4bXTugEjHSw==`
+
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-signing-a-response-using-ec
+	EcdsaP256SignatureBase = `"@status": 200
+"content-type": application/json
+"content-digest": sha-512=:mEWXIS7MaLRuGgxOBdODa3xqM1XdEvxoYhvlCFJ41QJgJc4GTsPp29l5oGX69wWdXymyU0rjJuahq4l5aGgfLQ==:
+"content-length": 23
+"@signature-params": ("@status" "content-type" "content-digest" "content-length");created=1618884473;keyid="test-key-ecc-p256"`
+	EcdsaP256Signature = "wNmSUAhwb5LxtOtOpNa6W5xj067m5hFrj0XQ4fvpaCLx0NKocgPquLgyahnzDnDAUy5eCdlYUEkLIj+32oiasw=="
+
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-signing-a-request-using-ed2
+	Ed25519SignatureBase = `"date": Tue, 20 Apr 2021 02:07:55 GMT
+"@method": POST
+"@path": /foo
+"@authority": example.com
+"content-type": application/json
+"content-length": 18
+"@signature-params": ("date" "@method" "@path" "@authority" "content-type" "content-length");created=1618884473;keyid="test-key-ed25519"`
+	Ed25519Signature = "wqcAqbmYJ2ji2glfAMaRy4gruYYnx2nEFN2HN6jrnDnQCK1u02Gb04v9EDgwUPiu4A0w6vuQv5lIp5WPpBKRCw=="
+)
+
+func mustDecodeBase64(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func mustParsePublicKey(t *testing.T, s string) any {
+	t.Helper()
+
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		t.Fatal("invalid PEM")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestRsaPssSha512VerifyingAlgorithm_Verify(t *testing.T) {
+	key := mustParsePublicKey(t, RsaPssTestPublicKey).(*rsa.PublicKey)
+
+	alg, err := verifier.NewRsaPssSha512VerifyingAlgorithm(key)
+	assert.NoError(t, err)
+	assert.Equal(t, "rsa-pss-sha512", alg.Name())
+
+	tests := []struct {
+		name          string
+		signatureBase string
+		signature     string
+	}{
+		{"minimal", RsaPssMinimalSignatureBase, RsaPssMinimalSignature},
+		{"selective", RsaPssSelectiveSignatureBase, RsaPssSelectiveSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := mustDecodeBase64(t, tt.signature)
+			assert.True(t, alg.Verify([]byte(tt.signatureBase), sig))
+			assert.False(t, alg.Verify([]byte(tt.signatureBase+" "), sig))
+		})
+	}
+}
+
+func TestNewRsaPssSha512VerifyingAlgorithm_KeyTooSmall(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+
+	_, err = verifier.NewRsaPssSha512VerifyingAlgorithm(&key.PublicKey)
+	assert.Error(t, err)
+
+	_, err = verifier.NewRsaPssSha512VerifyingAlgorithm(nil)
+	assert.Error(t, err)
+}
+
+func TestHmacSha256VerifyingAlgorithm_Verify(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-signing-a-request-using-hma
+	sigBase := []byte(`"date": Tue, 20 Apr 2021 02:07:55 GMT
+"@authority": example.com
+"content-type": application/json
+"@signature-params": ("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`)
+	secret := mustDecodeBase64(t, "uzvJfB4u3N0Jy4T7NZ75MDVcr8zSTInedJtkgcu46YW4XByzNJjxBdtjUkdJPBtbmHhIDi6pcl8jsasjlTMtDQ==")
+	sig := mustDecodeBase64(t, "pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=")
+
+	alg, err := verifier.NewHmacSha256VerifyingAlgorithm(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, "hmac-sha256", alg.Name())
+	assert.True(t, alg.Verify(sigBase, sig))
+	assert.False(t, alg.Verify(sigBase, sig[1:]))
+
+	_, err = verifier.NewHmacSha256VerifyingAlgorithm(secret[:verifier.HmacMinimumKeyBytes-1])
+	assert.Error(t, err)
+}
+
+func TestEd25519VerifyingAlgorithm_Verify(t *testing.T) {
+	key := mustParsePublicKey(t, Ed25519TestPublicKey).(ed25519.PublicKey)
+
+	alg, err := verifier.NewEd25519VerifyingAlgorithm(key)
+	assert.NoError(t, err)
+	assert.Equal(t, "ed25519", alg.Name())
+
+	sig := mustDecodeBase64(t, Ed25519Signature)
+	assert.True(t, alg.Verify([]byte(Ed25519SignatureBase), sig))
+	assert.False(t, alg.Verify([]byte(Ed25519SignatureBase+" "), sig))
+
+	_, err = verifier.NewEd25519VerifyingAlgorithm(key[1:])
+	assert.Error(t, err)
+}
+
+func TestEcdsaP256Sha256VerifyingAlgorithm_Verify(t *testing.T) {
+	key := mustParsePublicKey(t, EcdsaP256TestPublicKey).(*ecdsa.PublicKey)
+	sig := mustDecodeBase64(t, EcdsaP256Signature)
+
+	alg := verifier.NewEcdsaSha256VerifyingAlgorithm(key)
+	assert.Equal(t, "ecdsa-p256-sha256", alg.Name())
+	assert.Equal(t, verifier.EcdsaRawEncoding, alg.Encoding())
+	assert.True(t, alg.Verify([]byte(EcdsaP256SignatureBase), sig))
+	assert.False(t, alg.Verify([]byte(EcdsaP256SignatureBase+" "), sig))
+	assert.False(t, alg.Verify([]byte(EcdsaP256SignatureBase), sig[1:]))
+
+	// the RFC signature is not DER encoded
+	der := verifier.NewEcdsaSha256VerifyingAlgorithm(key, verifier.WithEcdsaDerEncoding())
+	assert.Equal(t, verifier.EcdsaDerEncoding, der.Encoding())
+	assert.False(t, der.Verify([]byte(EcdsaP256SignatureBase), sig))
+}
+
+func signEcdsaRaw(t *testing.T, key *ecdsa.PrivateKey, digest []byte) []byte {
+	t.Helper()
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := make([]byte, verifier.EcdsaSignatureSize(key.Curve))
+	r.FillBytes(sig[:len(sig)/2])
+	s.FillBytes(sig[len(sig)/2:])
+	return sig
+}
+
+func TestEcdsaP384Sha384VerifyingAlgorithm_Verify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	msg := []byte(`"@signature-params": ();created=1618884473;keyid="test-key-ecc-p384"`)
+	sha2Digest := sha512.Sum384(msg)
+	sha3Digest := sha3.Sum384(msg)
+	sha2Sig := signEcdsaRaw(t, key, sha2Digest[:])
+	sha3Sig := signEcdsaRaw(t, key, sha3Digest[:])
+
+	alg := verifier.NewEcdsaSha384VerifyingAlgorithm(&key.PublicKey)
+	assert.Equal(t, "ecdsa-p384-sha384", alg.Name())
+	assert.True(t, alg.Verify(msg, sha2Sig))
+	assert.False(t, alg.Verify(msg, sha3Sig))
+
+	legacy := verifier.NewEcdsaSha384VerifyingAlgorithm(&key.PublicKey, verifier.WithLegacySha3Fallback())
+	assert.True(t, legacy.Verify(msg, sha2Sig))
+	assert.True(t, legacy.Verify(msg, sha3Sig))
+	assert.False(t, legacy.Verify(append(msg, ' '), sha3Sig))
+}
+
+func TestEcdsaVerifyingAlgorithm_Verify_Concurrent(t *testing.T) {
+	p256Key := mustParsePublicKey(t, EcdsaP256TestPublicKey).(*ecdsa.PublicKey)
+	p256Sig := mustDecodeBase64(t, EcdsaP256Signature)
+	p256 := verifier.NewEcdsaSha256VerifyingAlgorithm(p256Key)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	msg := []byte(`"@signature-params": ();created=1618884473;keyid="test-key-ecc-p384"`)
+	sha3Digest := sha3.Sum384(msg)
+	sha3Sig := signEcdsaRaw(t, p384Key, sha3Digest[:])
+	p384 := verifier.NewEcdsaSha384VerifyingAlgorithm(&p384Key.PublicKey, verifier.WithLegacySha3Fallback())
+
+	// the algorithms are shared by concurrent requests
+	// so each call has to hash on its own
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.True(t, p256.Verify([]byte(EcdsaP256SignatureBase), p256Sig))
+			assert.True(t, p384.Verify(msg, sha3Sig))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewVerifyingAlgorithm(t *testing.T) {
+	rsaKey := mustParsePublicKey(t, RsaPssTestPublicKey).(*rsa.PublicKey)
+	edKey := mustParsePublicKey(t, Ed25519TestPublicKey).(ed25519.PublicKey)
+	ecKey := mustParsePublicKey(t, EcdsaP256TestPublicKey).(*ecdsa.PublicKey)
+
+	tests := []struct {
+		name string
+		key  any
+	}{
+		{httpsig.AlgorithmEcdsaP256Sha256, ecKey},
+		{httpsig.AlgorithmRsaPssSha512, rsaKey},
+		{httpsig.AlgorithmEd25519, edKey},
+		{httpsig.AlgorithmHmacSha256, make([]byte, 32)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alg, err := verifier.NewVerifyingAlgorithm(tt.name, tt.key)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.name, alg.Name())
+		})
+	}
+
+	_, err := verifier.NewVerifyingAlgorithm(httpsig.AlgorithmEcdsaP384Sha384, ecKey)
+	assert.Error(t, err)
+
+	_, err = verifier.NewVerifyingAlgorithm(httpsig.AlgorithmRsaV15Sha256, rsaKey)
+	assert.ErrorIs(t, err, httpsig.ErrUnknownAlgorithm)
+
+	// typed nil keys
+	_, err = verifier.NewVerifyingAlgorithm(httpsig.AlgorithmEcdsaP256Sha256, (*ecdsa.PublicKey)(nil))
+	assert.ErrorIs(t, err, httpsig.ErrNilKey)
+	_, err = verifier.NewVerifyingAlgorithm(httpsig.AlgorithmRsaPssSha512, (*rsa.PublicKey)(nil))
+	assert.ErrorIs(t, err, httpsig.ErrNilKey)
+	_, err = verifier.NewVerifyingAlgorithm(httpsig.AlgorithmEd25519, nil)
+	assert.ErrorIs(t, err, httpsig.ErrNilKey)
+}
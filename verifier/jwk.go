@@ -0,0 +1,77 @@
+package verifier
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ccldd/httpsig"
+)
+
+var (
+	// ErrUnusableJWK is reported for the keys of a JWK Set which are skipped
+	ErrUnusableJWK = errors.New("unusable JWK")
+
+	// ErrNoUsableJWK is returned when every key of a JWK Set is skipped
+	ErrNoUsableJWK = errors.New("JWK Set has no usable keys")
+)
+
+// NewVerifyingAlgorithmFromJWK creates the verifying algorithm for the public key
+// in jwk. The algorithm is chosen with httpsig.JWK.AlgorithmName, so a JWS alg
+// such as RS256 needs its RFC 9421 algorithm in the registry.
+func NewVerifyingAlgorithmFromJWK(jwk httpsig.JWK) (VerifyingAlgorithm, error) {
+	return newVerifyingAlgorithmFromJWK(httpsig.DefaultAlgorithmRegistry, jwk)
+}
+
+func newVerifyingAlgorithmFromJWK(r *httpsig.AlgorithmRegistry, jwk httpsig.JWK) (VerifyingAlgorithm, error) {
+	name, err := jwk.AlgorithmName()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := jwk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return newVerifyingAlgorithm(r, name, key)
+}
+
+// NewKeyResolverFromJWKSet creates an InMemoryKeyResolver with the keys
+// in set using their kid as the keyid. Keys for encryption are skipped.
+//
+// Keys without kid or with an unsupported algorithm, curve or key size are
+// skipped so that one such key does not make the whole set unusable.
+// skipped has an error wrapping ErrUnusableJWK for every skipped key.
+// err wrapping ErrNoUsableJWK is only returned if every key is skipped.
+func NewKeyResolverFromJWKSet(set httpsig.JWKSet) (r *InMemoryKeyResolver, skipped []error, err error) {
+	return newKeyResolverFromJWKSet(httpsig.DefaultAlgorithmRegistry, set)
+}
+
+func newKeyResolverFromJWKSet(registry *httpsig.AlgorithmRegistry, set httpsig.JWKSet) (*InMemoryKeyResolver, []error, error) {
+	r := NewInMemoryKeyResolver()
+	skipped := make([]error, 0)
+	usable := 0
+	for i, jwk := range set.Keys {
+		if jwk.Use == "enc" {
+			continue
+		}
+		if jwk.KeyId == "" {
+			skipped = append(skipped, fmt.Errorf("%w: JWK %d has no kid", ErrUnusableJWK, i))
+			continue
+		}
+
+		alg, err := newVerifyingAlgorithmFromJWK(registry, jwk)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("%w: JWK %q: %w", ErrUnusableJWK, jwk.KeyId, err))
+			continue
+		}
+		r.AddKey(jwk.KeyId, alg)
+		usable++
+	}
+
+	if usable == 0 && len(skipped) > 0 {
+		return nil, skipped, fmt.Errorf("%w: %w", ErrNoUsableJWK, errors.Join(skipped...))
+	}
+
+	return r, skipped, nil
+}
@@ -0,0 +1,121 @@
+package verifier_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+const TestJWKSet = `{
+	"keys": [
+		{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"kid": "test-key-ed25519",
+			"x": "JrQLj5P_89iXES9-vFgrIy29clF9CC_oPPsw3c5D0bs"
+		},
+		{
+			"kty": "EC",
+			"crv": "P-256",
+			"kid": "test-key-ecc-p256",
+			"alg": "ES256",
+			"x": "qIVYZVLCrPZHGHjP17CTW0_-D9Lfw0EkjqF7xB4FivA",
+			"y": "Mc4nN9LTDOBhfoUeg8Ye9WedFRhnZXZJA12Qp0zZ6F0"
+		},
+		{
+			"kty": "EC",
+			"crv": "P-256",
+			"kid": "encryption-key",
+			"use": "enc",
+			"x": "qIVYZVLCrPZHGHjP17CTW0_-D9Lfw0EkjqF7xB4FivA",
+			"y": "Mc4nN9LTDOBhfoUeg8Ye9WedFRhnZXZJA12Qp0zZ6F0"
+		}
+	]
+}`
+
+func TestNewKeyResolverFromJWKSet(t *testing.T) {
+	set, err := httpsig.ParseJWKSet([]byte(TestJWKSet))
+	assert.NoError(t, err)
+
+	r, skipped, err := verifier.NewKeyResolverFromJWKSet(set)
+	assert.NoError(t, err)
+	assert.Empty(t, skipped)
+
+	alg, err := r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.AlgorithmEd25519, alg.Name())
+	assert.True(t, alg.Verify([]byte(Ed25519SignatureBase), mustDecodeBase64(t, Ed25519Signature)))
+
+	alg, err = r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: "test-key-ecc-p256"})
+	assert.NoError(t, err)
+	assert.Equal(t, httpsig.AlgorithmEcdsaP256Sha256, alg.Name())
+	assert.True(t, alg.Verify([]byte(EcdsaP256SignatureBase), mustDecodeBase64(t, EcdsaP256Signature)))
+
+	_, err = r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: "encryption-key"})
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+}
+
+func TestNewKeyResolverFromJWKSet_Invalid(t *testing.T) {
+	r, skipped, err := verifier.NewKeyResolverFromJWKSet(httpsig.JWKSet{Keys: []httpsig.JWK{{KeyType: "oct", K: "c2VjcmV0"}}})
+	assert.ErrorIs(t, err, verifier.ErrNoUsableJWK)
+	assert.Nil(t, r)
+	assert.Len(t, skipped, 1)
+	assert.ErrorIs(t, skipped[0], verifier.ErrUnusableJWK)
+
+	// the secret is too short for hmac-sha256
+	_, skipped, err = verifier.NewKeyResolverFromJWKSet(httpsig.JWKSet{Keys: []httpsig.JWK{{KeyType: "oct", KeyId: "short", K: "c2VjcmV0"}}})
+	assert.ErrorIs(t, err, verifier.ErrNoUsableJWK)
+	assert.ErrorIs(t, err, verifier.ErrUnusableJWK)
+	assert.Len(t, skipped, 1)
+
+	// a JWK Set without keys for verifying is not an error
+	r, skipped, err = verifier.NewKeyResolverFromJWKSet(httpsig.JWKSet{})
+	assert.NoError(t, err)
+	assert.NotNil(t, r)
+	assert.Empty(t, skipped)
+}
+
+func TestNewKeyResolverFromJWKSet_Mixed(t *testing.T) {
+	set, err := httpsig.ParseJWKSet([]byte(TestJWKSet))
+	assert.NoError(t, err)
+
+	rsaJWK, err := httpsig.NewJWKFromPublicKey(mustParsePublicKey(t, RsaPssTestPublicKey))
+	assert.NoError(t, err)
+	rsaJWK.KeyId = "legacy-rs256"
+	rsaJWK.Alg = httpsig.JWSAlgorithmRS256
+
+	set.Keys = append(set.Keys,
+		// no kid
+		httpsig.JWK{KeyType: "OKP", Curve: "Ed25519", X: "JrQLj5P_89iXES9-vFgrIy29clF9CC_oPPsw3c5D0bs"},
+		// rsa-v1_5-sha256 is not in the default registry
+		rsaJWK,
+		// unsupported curve
+		httpsig.JWK{KeyType: "EC", Curve: "P-521", KeyId: "p521", X: "AQ", Y: "AQ"},
+		// the secret is too short for hmac-sha256
+		httpsig.JWK{KeyType: "oct", KeyId: "short", K: "c2VjcmV0"},
+	)
+
+	r, skipped, err := verifier.NewKeyResolverFromJWKSet(set)
+	assert.NoError(t, err)
+	assert.Len(t, skipped, 4)
+
+	err = errors.Join(skipped...)
+	assert.ErrorIs(t, err, verifier.ErrUnusableJWK)
+	assert.ErrorContains(t, err, "JWK 3 has no kid")
+	assert.ErrorContains(t, err, `"legacy-rs256"`)
+	assert.ErrorContains(t, err, `"p521"`)
+	assert.ErrorContains(t, err, `"short"`)
+
+	for _, keyId := range []string{"test-key-ed25519", "test-key-ecc-p256"} {
+		_, err := r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: keyId})
+		assert.NoError(t, err, keyId)
+	}
+	for _, keyId := range []string{"legacy-rs256", "p521", "short"} {
+		_, err := r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: keyId})
+		assert.ErrorIs(t, err, verifier.ErrKeyNotFound, keyId)
+	}
+}
@@ -0,0 +1,289 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ccldd/httpsig"
+)
+
+const (
+	// DefaultJWKSCacheDuration is how long keys are cached
+	// when the response has no Cache-Control max-age
+	DefaultJWKSCacheDuration = 5 * time.Minute
+
+	// DefaultJWKSMinRefreshInterval is the minimum time between fetches
+	DefaultJWKSMinRefreshInterval = 30 * time.Second
+
+	// DefaultJWKSMaxStaleness is how long expired keys are used
+	// while the JWK Set cannot be fetched
+	DefaultJWKSMaxStaleness = time.Hour
+
+	maxJWKSResponseBytes = 1 << 20
+)
+
+type JWKSOption func(*JWKSKeyResolver)
+
+// WithHttpClient sets the client used to fetch the JWK Set
+func WithHttpClient(client *http.Client) JWKSOption {
+	return func(r *JWKSKeyResolver) {
+		r.client = client
+	}
+}
+
+// WithDefaultCacheDuration sets how long keys are cached
+// when the response has no Cache-Control max-age
+func WithDefaultCacheDuration(d time.Duration) JWKSOption {
+	return func(r *JWKSKeyResolver) {
+		r.defaultCacheDuration = d
+	}
+}
+
+// WithMinRefreshInterval sets the minimum time between fetches
+// which rate limits refreshes caused by unknown keyids
+func WithMinRefreshInterval(d time.Duration) JWKSOption {
+	return func(r *JWKSKeyResolver) {
+		r.minRefreshInterval = d
+	}
+}
+
+// WithMaxStaleness sets how long expired keys are used
+// while the JWK Set cannot be fetched
+func WithMaxStaleness(d time.Duration) JWKSOption {
+	return func(r *JWKSKeyResolver) {
+		r.maxStaleness = d
+	}
+}
+
+// WithJWKSAlgorithmRegistry sets the registry used to create the algorithms
+// of the keys instead of httpsig.DefaultAlgorithmRegistry, e.g. a registry
+// with RegisterLegacyAlgorithms to accept RS256 keys
+func WithJWKSAlgorithmRegistry(registry *httpsig.AlgorithmRegistry) JWKSOption {
+	return func(r *JWKSKeyResolver) {
+		r.registry = registry
+	}
+}
+
+// JWKSKeyResolver is a KeyResolver which fetches a JWK Set from a URL
+// and looks up keys by keyid. It is safe for concurrent use.
+//
+// Keys are cached for the Cache-Control max-age of the response.
+// An unknown keyid triggers a refresh, at most once every minimum refresh interval.
+// If refreshing fails, the previously fetched keys are used
+// for at most the max staleness after they expire.
+//
+// Keys which cannot be used, e.g. because their algorithm is not in the
+// registry, are skipped and the other keys of the JWK Set are still used.
+type JWKSKeyResolver struct {
+	url                  string
+	client               *http.Client
+	registry             *httpsig.AlgorithmRegistry
+	defaultCacheDuration time.Duration
+	minRefreshInterval   time.Duration
+	maxStaleness         time.Duration
+
+	// fetchMu serialises fetches so concurrent lookups
+	// do not fetch the JWK Set more than once
+	fetchMu sync.Mutex
+
+	mu        sync.RWMutex
+	keys      *InMemoryKeyResolver
+	skipped   []error
+	expiresAt time.Time
+	fetchedAt time.Time
+}
+
+func NewJWKSKeyResolver(url string, opts ...JWKSOption) *JWKSKeyResolver {
+	r := &JWKSKeyResolver{
+		url:                  url,
+		client:               http.DefaultClient,
+		registry:             httpsig.DefaultAlgorithmRegistry,
+		defaultCacheDuration: DefaultJWKSCacheDuration,
+		minRefreshInterval:   DefaultJWKSMinRefreshInterval,
+		maxStaleness:         DefaultJWKSMaxStaleness,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+func (r *JWKSKeyResolver) ResolveKey(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, error) {
+	var refreshErr error
+	refreshed := false
+	if r.expired() {
+		refreshErr = r.refresh(ctx, false)
+		refreshed = refreshErr == nil
+	}
+
+	// expired keys are used while refreshing fails unless they are too stale
+	keys, skipped := r.cachedKeys()
+	if keys == nil || refreshErr != nil && r.stale() {
+		return nil, fmt.Errorf("error fetching JWK Set: %w", refreshErr)
+	}
+
+	alg, err := keys.ResolveKey(ctx, params)
+	if !errors.Is(err, ErrKeyNotFound) {
+		return alg, err
+	}
+
+	// the key may have been added since the last fetch
+	// unless the JWK Set has just been fetched
+	if !refreshed {
+		if refreshErr = r.refresh(ctx, true); refreshErr != nil {
+			return nil, errors.Join(append([]error{err, refreshErr}, skipped...)...)
+		}
+
+		keys, skipped = r.cachedKeys()
+		alg, err = keys.ResolveKey(ctx, params)
+	}
+	if err != nil {
+		return nil, errors.Join(append([]error{err}, skipped...)...)
+	}
+
+	return alg, nil
+}
+
+// Refresh fetches the JWK Set regardless of the cache.
+// The usable keys are loaded even if some keys are skipped,
+// which are reported by SkippedKeys.
+func (r *JWKSKeyResolver) Refresh(ctx context.Context) error {
+	r.fetchMu.Lock()
+	defer r.fetchMu.Unlock()
+
+	return r.fetch(ctx)
+}
+
+// SkippedKeys returns an error wrapping ErrUnusableJWK for every key
+// of the last fetched JWK Set which could not be used
+func (r *JWKSKeyResolver) SkippedKeys() []error {
+	_, skipped := r.cachedKeys()
+	return skipped
+}
+
+// cachedKeys returns the fetched keys and the errors for the skipped keys
+func (r *JWKSKeyResolver) cachedKeys() (*InMemoryKeyResolver, []error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.keys, r.skipped
+}
+
+func (r *JWKSKeyResolver) expired() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.keys == nil || !time.Now().Before(r.expiresAt)
+}
+
+// stale reports whether the keys expired more than the max staleness ago
+func (r *JWKSKeyResolver) stale() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return time.Since(r.expiresAt) > r.maxStaleness
+}
+
+// refresh fetches the JWK Set unless it was fetched within the
+// minimum refresh interval. If unknownKey is false, it also skips
+// fetching when another caller already refreshed the cache.
+func (r *JWKSKeyResolver) refresh(ctx context.Context, unknownKey bool) error {
+	r.fetchMu.Lock()
+	defer r.fetchMu.Unlock()
+
+	if !unknownKey && !r.expired() {
+		return nil
+	}
+
+	r.mu.RLock()
+	fetchedAt := r.fetchedAt
+	r.mu.RUnlock()
+	if !fetchedAt.IsZero() && time.Since(fetchedAt) < r.minRefreshInterval {
+		return fmt.Errorf("JWK Set was refreshed less than %s ago", r.minRefreshInterval)
+	}
+
+	return r.fetch(ctx)
+}
+
+func (r *JWKSKeyResolver) fetch(ctx context.Context) (err error) {
+	defer func() {
+		// rate limit failed fetches as well unless ctx was cancelled
+		// or timed out, which says nothing about the JWKS endpoint
+		if err == nil || ctx.Err() == nil {
+			r.mu.Lock()
+			r.fetchedAt = time.Now()
+			r.mu.Unlock()
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/jwk-set+json, application/json")
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status fetching JWK Set: %s", res.Status)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, maxJWKSResponseBytes))
+	if err != nil {
+		return err
+	}
+
+	set, err := httpsig.ParseJWKSet(b)
+	if err != nil {
+		return err
+	}
+
+	// keys which cannot be used are skipped
+	keys, skipped, err := newKeyResolverFromJWKSet(r.registry, set)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys = keys
+	r.skipped = skipped
+	r.expiresAt = time.Now().Add(r.cacheDuration(res.Header))
+
+	return nil
+}
+
+// cacheDuration returns the max-age of the Cache-Control header
+func (r *JWKSKeyResolver) cacheDuration(header http.Header) time.Duration {
+	cacheControl := header.Get("Cache-Control")
+	if cacheControl == "" {
+		return r.defaultCacheDuration
+	}
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds >= 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	return r.defaultCacheDuration
+}
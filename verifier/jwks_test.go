@@ -0,0 +1,223 @@
+package verifier_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+type jwksServer struct {
+	*httptest.Server
+	requests     atomic.Int32
+	fail         atomic.Bool
+	body         atomic.Value
+	cacheControl string
+}
+
+func newJWKSServer(t *testing.T, cacheControl string) *jwksServer {
+	s := &jwksServer{cacheControl: cacheControl}
+	s.body.Store(`{"keys":[]}`)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if s.cacheControl != "" {
+			w.Header().Set("Cache-Control", s.cacheControl)
+		}
+		w.Header().Set("Content-Type", "application/jwk-set+json")
+		w.Write([]byte(s.body.Load().(string)))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestJWKSKeyResolver_Cache(t *testing.T) {
+	server := newJWKSServer(t, "public, max-age=3600")
+	server.body.Store(TestJWKSet)
+
+	r := verifier.NewJWKSKeyResolver(server.URL, verifier.WithMinRefreshInterval(0))
+	ctx := context.Background()
+
+	alg, err := r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.NoError(t, err)
+	assert.True(t, alg.Verify([]byte(Ed25519SignatureBase), mustDecodeBase64(t, Ed25519Signature)))
+
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ecc-p256"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), server.requests.Load())
+}
+
+func TestJWKSKeyResolver_UnknownKeyId(t *testing.T) {
+	server := newJWKSServer(t, "max-age=3600")
+
+	r := verifier.NewJWKSKeyResolver(server.URL, verifier.WithMinRefreshInterval(time.Hour))
+	ctx := context.Background()
+
+	_, err := r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+	assert.Equal(t, int32(1), server.requests.Load())
+
+	// the refresh for the unknown keyid is rate limited
+	server.body.Store(TestJWKSet)
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+	assert.Equal(t, int32(1), server.requests.Load())
+
+	// without rate limiting the new key is fetched
+	r = verifier.NewJWKSKeyResolver(server.URL, verifier.WithMinRefreshInterval(0))
+	server.body.Store(`{"keys":[]}`)
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+
+	// the first lookup does not fetch again for the unknown keyid
+	assert.Equal(t, int32(2), server.requests.Load())
+
+	server.body.Store(TestJWKSet)
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), server.requests.Load())
+}
+
+func TestJWKSKeyResolver_StaleFallback(t *testing.T) {
+	server := newJWKSServer(t, "no-cache")
+	server.body.Store(TestJWKSet)
+
+	r := verifier.NewJWKSKeyResolver(server.URL, verifier.WithMinRefreshInterval(0))
+	ctx := context.Background()
+
+	_, err := r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.NoError(t, err)
+
+	// the cache has expired but the endpoint is down
+	server.fail.Store(true)
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), server.requests.Load())
+}
+
+func TestJWKSKeyResolver_MaxStaleness(t *testing.T) {
+	server := newJWKSServer(t, "no-cache")
+	server.body.Store(TestJWKSet)
+
+	r := verifier.NewJWKSKeyResolver(server.URL, verifier.WithMinRefreshInterval(0), verifier.WithMaxStaleness(0))
+	ctx := context.Background()
+
+	_, err := r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.NoError(t, err)
+
+	// the expired keys are not used once they are too stale
+	server.fail.Store(true)
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.Error(t, err)
+
+	server.fail.Store(false)
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.NoError(t, err)
+}
+
+func TestJWKSKeyResolver_UnusableKeys(t *testing.T) {
+	set, err := httpsig.ParseJWKSet([]byte(TestJWKSet))
+	assert.NoError(t, err)
+
+	rsaJWK, err := httpsig.NewJWKFromPublicKey(mustParsePublicKey(t, RsaPssTestPublicKey))
+	assert.NoError(t, err)
+	rsaJWK.KeyId = "legacy-rs256"
+	rsaJWK.Alg = httpsig.JWSAlgorithmRS256
+	set.Keys = append(set.Keys, rsaJWK, httpsig.JWK{KeyType: "EC", Curve: "P-521", KeyId: "p521", X: "AQ", Y: "AQ"})
+
+	b, err := json.Marshal(set)
+	assert.NoError(t, err)
+	server := newJWKSServer(t, "max-age=3600")
+	server.body.Store(string(b))
+	ctx := context.Background()
+
+	// the other keys are used
+	r := verifier.NewJWKSKeyResolver(server.URL)
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.NoError(t, err)
+
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "legacy-rs256"})
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+	assert.ErrorIs(t, err, verifier.ErrUnusableJWK)
+
+	assert.NoError(t, r.Refresh(ctx))
+	assert.Len(t, r.SkippedKeys(), 2)
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ecc-p256"})
+	assert.NoError(t, err)
+
+	// a JWK Set without usable keys fails to refresh
+	// and the previously fetched keys are kept
+	b, err = json.Marshal(httpsig.JWKSet{Keys: []httpsig.JWK{rsaJWK}})
+	assert.NoError(t, err)
+	server.body.Store(string(b))
+	assert.ErrorIs(t, r.Refresh(ctx), verifier.ErrNoUsableJWK)
+	_, err = r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.NoError(t, err)
+
+	// legacy algorithms are opted into with the registry
+	registry := httpsig.NewAlgorithmRegistry()
+	verifier.RegisterAlgorithms(registry)
+	verifier.RegisterLegacyAlgorithms(registry)
+
+	r = verifier.NewJWKSKeyResolver(server.URL, verifier.WithJWKSAlgorithmRegistry(registry))
+	alg, err := r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "legacy-rs256"})
+	assert.NoError(t, err)
+	assert.NotNil(t, alg)
+}
+
+func TestJWKSKeyResolver_Unavailable(t *testing.T) {
+	server := newJWKSServer(t, "")
+	server.fail.Store(true)
+
+	r := verifier.NewJWKSKeyResolver(server.URL, verifier.WithHttpClient(server.Client()))
+	_, err := r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.Error(t, err)
+}
+
+func TestJWKSKeyResolver_CancelledContext(t *testing.T) {
+	server := newJWKSServer(t, "max-age=3600")
+	server.body.Store(TestJWKSet)
+
+	r := verifier.NewJWKSKeyResolver(server.URL, verifier.WithMinRefreshInterval(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := r.ResolveKey(ctx, verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// a cancelled fetch is not rate limited
+	_, err = r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: "test-key-ed25519"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), server.requests.Load())
+
+	// a failed fetch is
+	server.fail.Store(true)
+	assert.Error(t, r.Refresh(context.Background()))
+	server.fail.Store(false)
+	_, err = r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: "test-key-unknown"})
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+	assert.Equal(t, int32(2), server.requests.Load())
+}
+
+func TestJWKSKeyResolver_Verifier(t *testing.T) {
+	server := newJWKSServer(t, "")
+	server.body.Store(TestJWKSet)
+
+	v, err := verifier.NewWithKeyResolver(verifier.NewJWKSKeyResolver(server.URL), verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	req := newSignedRequest(t)
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,49 @@
+package verifier
+
+import (
+	"github.com/ccldd/httpsig"
+)
+
+// jwsRegistry creates the algorithms behind the JWS algorithm names.
+// RS256 is included since using it is agreed on out of band.
+var jwsRegistry = newJWSRegistry()
+
+func newJWSRegistry() *httpsig.AlgorithmRegistry {
+	r := httpsig.NewAlgorithmRegistry()
+	RegisterAlgorithms(r)
+	RegisterLegacyAlgorithms(r)
+
+	return r
+}
+
+// JWSVerifyingAlgorithm is a VerifyingAlgorithm identified by a JWS
+// algorithm name instead of a RFC 9421 algorithm name.
+// Its Name is the JWS algorithm name.
+//
+// Signatures verified with it must not have the alg signature parameter.
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#section-3.3.7
+type JWSVerifyingAlgorithm struct {
+	VerifyingAlgorithm
+	name string
+}
+
+// NewJWSVerifyingAlgorithm creates the verifying algorithm for the
+// JWS algorithm name, e.g. httpsig.JWSAlgorithmES256
+func NewJWSVerifyingAlgorithm(name string, key any) (*JWSVerifyingAlgorithm, error) {
+	rfcName, err := httpsig.AlgorithmNameFromJWS(name)
+	if err != nil {
+		return nil, err
+	}
+
+	alg, err := newVerifyingAlgorithm(jwsRegistry, rfcName, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWSVerifyingAlgorithm{VerifyingAlgorithm: alg, name: name}, nil
+}
+
+func (alg JWSVerifyingAlgorithm) Name() string {
+	return alg.name
+}
@@ -0,0 +1,66 @@
+package verifier_test
+
+import (
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJWSVerifyingAlgorithm(t *testing.T) {
+	tests := []struct {
+		jws           string
+		key           string
+		signatureBase string
+		signature     string
+	}{
+		{httpsig.JWSAlgorithmPS512, RsaPssTestPublicKey, RsaPssMinimalSignatureBase, RsaPssMinimalSignature},
+		{httpsig.JWSAlgorithmES256, EcdsaP256TestPublicKey, EcdsaP256SignatureBase, EcdsaP256Signature},
+		{httpsig.JWSAlgorithmEdDSA, Ed25519TestPublicKey, Ed25519SignatureBase, Ed25519Signature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.jws, func(t *testing.T) {
+			key, err := httpsig.ParsePublicKey([]byte(tt.key))
+			assert.NoError(t, err)
+
+			alg, err := verifier.NewJWSVerifyingAlgorithm(tt.jws, key)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.jws, alg.Name())
+			assert.True(t, alg.Verify([]byte(tt.signatureBase), mustDecodeBase64(t, tt.signature)))
+		})
+	}
+
+	_, err := verifier.NewJWSVerifyingAlgorithm(httpsig.AlgorithmEcdsaP256Sha256, &ECCP256TestKey.PublicKey)
+	assert.ErrorIs(t, err, httpsig.ErrUnknownAlgorithm)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_JWSAlgParameter(t *testing.T) {
+	alg, err := verifier.NewJWSVerifyingAlgorithm(httpsig.JWSAlgorithmES256, &ECCP256TestKey.PublicKey)
+	assert.NoError(t, err)
+
+	v, err := verifier.New(alg, verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(newSignedRequest(t))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(newSignedRequest(t, signer.WithAlg()))
+	assert.ErrorIs(t, err, httpsig.ErrJWSAlgParameter)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_JWKAlgParameter(t *testing.T) {
+	set, err := httpsig.ParseJWKSet([]byte(TestJWKSet))
+	assert.NoError(t, err)
+	keys, _, err := verifier.NewKeyResolverFromJWKSet(set)
+	assert.NoError(t, err)
+
+	v, err := verifier.NewWithKeyResolver(keys, verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	// the ES256 JWK is used as ecdsa-p256-sha256
+	_, err = v.VerifyRequest(newSignedRequest(t, signer.WithAlg()))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,213 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ccldd/httpsig"
+)
+
+var (
+	ErrKeyNotFound   = errors.New("key not found")
+	ErrKeyConstraint = errors.New("signature does not satisfy the key constraints")
+)
+
+// KeyParameters are the signature parameters from Signature-Input
+// used to look up the key. Parameters absent from the signature are empty.
+type KeyParameters struct {
+	KeyId string
+	Alg   string
+	Tag   string
+}
+
+// KeyParametersFromSignatureParameters collects the keyid,
+// alg and tag signature parameters
+func KeyParametersFromSignatureParameters(sigParams []httpsig.SignatureParameter) KeyParameters {
+	var params KeyParameters
+	for _, p := range sigParams {
+		switch pp := p.(type) {
+		case httpsig.KeyId:
+			params.KeyId = string(pp)
+		case httpsig.Alg:
+			params.Alg = string(pp)
+		case httpsig.Tag:
+			params.Tag = string(pp)
+		}
+	}
+
+	return params
+}
+
+// KeyResolver returns the VerifyingAlgorithm to verify a signature with.
+// It returns an error wrapping ErrKeyNotFound if there is no key for the parameters.
+type KeyResolver interface {
+	ResolveKey(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, error)
+}
+
+// KeyMetadata constrains the signatures a key can verify
+// so that a key cannot be used to sign arbitrary messages.
+// Empty fields do not constrain the signature.
+type KeyMetadata struct {
+	// Algorithms are the algorithm names the key can be used with.
+	// Keys with a fixed algorithm, such as the keys of an InMemoryKeyResolver,
+	// always resolve to the same algorithm so it only restricts algorithms
+	// created from the alg signature parameter, e.g. with NewWithKey.
+	Algorithms []string
+
+	// Tags are the values of the tag signature parameter the key is valid for.
+	// Signatures without a tag are rejected.
+	Tags []string
+
+	// RequiredComponents are the components which the signature must cover
+	// with the same parameters, e.g. "@method" or "content-digest"
+	RequiredComponents []httpsig.ComponentIdentifier
+}
+
+// Validate checks the resolved algorithm, the key parameters
+// and the covered components satisfy the constraints
+func (md KeyMetadata) Validate(alg VerifyingAlgorithm, params KeyParameters, components []httpsig.ComponentIdentifier) error {
+	errs := make([]error, 0)
+
+	if len(md.Algorithms) > 0 && !slices.Contains(md.Algorithms, alg.Name()) {
+		errs = append(errs, fmt.Errorf("%w: algorithm %q is not allowed", ErrKeyConstraint, alg.Name()))
+	}
+	if len(md.Tags) > 0 && !slices.Contains(md.Tags, params.Tag) {
+		errs = append(errs, fmt.Errorf("%w: tag %q is not allowed", ErrKeyConstraint, params.Tag))
+	}
+	for _, required := range md.RequiredComponents {
+		if !slices.ContainsFunc(components, required.Equal) {
+			errs = append(errs, fmt.Errorf("%w: %s is not covered", ErrKeyConstraint, required))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// KeyMetadataResolver is a KeyResolver which also returns the constraints
+// of the key. HttpMessageVerifier enforces them if its resolver implements it.
+// Use NewKeyMetadataResolver to add constraints to any KeyResolver.
+type KeyMetadataResolver interface {
+	KeyResolver
+	ResolveKeyMetadata(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, KeyMetadata, error)
+}
+
+// KeyMetadataFunc returns the constraints of the key resolved for params
+type KeyMetadataFunc func(ctx context.Context, params KeyParameters, alg VerifyingAlgorithm) (KeyMetadata, error)
+
+// keyMetadataResolver attaches the metadata from a KeyMetadataFunc
+// to the keys of a KeyResolver
+type keyMetadataResolver struct {
+	KeyResolver
+	metadata KeyMetadataFunc
+}
+
+// NewKeyMetadataResolver wraps resolver, e.g. a JWKSKeyResolver or X509KeyResolver,
+// so that the keys it resolves are constrained by the KeyMetadata returned by metadata
+func NewKeyMetadataResolver(resolver KeyResolver, metadata KeyMetadataFunc) KeyMetadataResolver {
+	return keyMetadataResolver{KeyResolver: resolver, metadata: metadata}
+}
+
+func (r keyMetadataResolver) ResolveKeyMetadata(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, KeyMetadata, error) {
+	alg, err := r.ResolveKey(ctx, params)
+	if err != nil || alg == nil {
+		return alg, KeyMetadata{}, err
+	}
+
+	metadata, err := r.metadata(ctx, params, alg)
+	if err != nil {
+		return nil, KeyMetadata{}, err
+	}
+
+	return alg, metadata, nil
+}
+
+// KeyResolverFunc is an adapter to use a function as a KeyResolver
+type KeyResolverFunc func(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, error)
+
+func (f KeyResolverFunc) ResolveKey(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, error) {
+	return f(ctx, params)
+}
+
+// InMemoryKeyResolver is a KeyMetadataResolver which looks up keys by keyid.
+// It is safe for concurrent use.
+type InMemoryKeyResolver struct {
+	mu   sync.RWMutex
+	keys map[string]inMemoryKey
+}
+
+type inMemoryKey struct {
+	alg      VerifyingAlgorithm
+	metadata KeyMetadata
+}
+
+func NewInMemoryKeyResolver() *InMemoryKeyResolver {
+	return &InMemoryKeyResolver{
+		keys: make(map[string]inMemoryKey),
+	}
+}
+
+// AddKey adds or replaces the key with keyId
+func (r *InMemoryKeyResolver) AddKey(keyId string, alg VerifyingAlgorithm) {
+	r.AddKeyWithMetadata(keyId, alg, KeyMetadata{})
+}
+
+// AddKeyWithMetadata adds or replaces the key with keyId
+// which can only verify signatures satisfying metadata
+func (r *InMemoryKeyResolver) AddKeyWithMetadata(keyId string, alg VerifyingAlgorithm, metadata KeyMetadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys[keyId] = inMemoryKey{alg: alg, metadata: metadata}
+}
+
+// RemoveKey removes the key with keyId
+func (r *InMemoryKeyResolver) RemoveKey(keyId string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.keys, keyId)
+}
+
+func (r *InMemoryKeyResolver) ResolveKey(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, error) {
+	alg, _, err := r.ResolveKeyMetadata(ctx, params)
+	return alg, err
+}
+
+func (r *InMemoryKeyResolver) ResolveKeyMetadata(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, KeyMetadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[params.KeyId]
+	if !ok {
+		return nil, KeyMetadata{}, fmt.Errorf("%w: keyid %q", ErrKeyNotFound, params.KeyId)
+	}
+
+	return key.alg, key.metadata, nil
+}
+
+// staticKeyResolver always resolves to the same algorithm
+type staticKeyResolver struct {
+	alg VerifyingAlgorithm
+}
+
+func (r staticKeyResolver) ResolveKey(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, error) {
+	return r.alg, nil
+}
+
+// registryKeyResolver creates the algorithm from
+// the alg signature parameter and a key
+type registryKeyResolver struct {
+	key      any
+	registry *httpsig.AlgorithmRegistry
+}
+
+func (r registryKeyResolver) ResolveKey(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, error) {
+	if params.Alg == "" {
+		return nil, ErrAlgParameter
+	}
+
+	return newVerifyingAlgorithm(r.registry, params.Alg, r.key)
+}
@@ -0,0 +1,196 @@
+package verifier_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyParametersFromSignatureParameters(t *testing.T) {
+	params := verifier.KeyParametersFromSignatureParameters([]httpsig.SignatureParameter{
+		httpsig.Created{},
+		httpsig.KeyId("test-key"),
+		httpsig.Alg(httpsig.AlgorithmEd25519),
+		httpsig.Tag("app"),
+	})
+
+	assert.Equal(t, verifier.KeyParameters{KeyId: "test-key", Alg: httpsig.AlgorithmEd25519, Tag: "app"}, params)
+}
+
+func TestInMemoryKeyResolver(t *testing.T) {
+	alg := verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey)
+
+	r := verifier.NewInMemoryKeyResolver()
+	r.AddKey(ECCP256TestKeyId, alg)
+
+	resolved, err := r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: ECCP256TestKeyId})
+	assert.NoError(t, err)
+	assert.Equal(t, alg, resolved)
+
+	r.RemoveKey(ECCP256TestKeyId)
+	_, err = r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: ECCP256TestKeyId})
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_KeyResolver(t *testing.T) {
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	edVerifier, err := verifier.NewEd25519VerifyingAlgorithm(edPub)
+	assert.NoError(t, err)
+
+	r := verifier.NewInMemoryKeyResolver()
+	r.AddKey(ECCP256TestKeyId, verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey))
+	r.AddKey("client-b", edVerifier)
+
+	v, err := verifier.NewWithKeyResolver(r, verifier.WithOnlySignature())
+	assert.NoError(t, err)
+
+	// client a
+	req := newSignedRequest(t)
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	// client b
+	edAlg, err := signer.NewEd25519SigningAlgorithm(edKey)
+	assert.NoError(t, err)
+	s, err := signer.New(edAlg, "sig1", signer.WithKeyId("client-b"), signer.WithAlg(), signer.WithMethod())
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, s.SignRequest(req))
+
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	// unknown client
+	s, err = signer.New(edAlg, "sig1", signer.WithKeyId("client-c"), signer.WithMethod())
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, s.SignRequest(req))
+
+	_, err = v.VerifyRequest(req)
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_KeyResolverFuncNil(t *testing.T) {
+	r := verifier.KeyResolverFunc(func(ctx context.Context, params verifier.KeyParameters) (verifier.VerifyingAlgorithm, error) {
+		return nil, nil
+	})
+
+	v, err := verifier.NewWithKeyResolver(r, verifier.WithOnlySignature())
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(newSignedRequest(t, signer.WithAlg()))
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_KeyMetadata(t *testing.T) {
+	// claims content-digest without signing it
+	addContentDigest := func(req *http.Request) {
+		sigInput := req.Header.Get("Signature-Input")
+		req.Header.Set("Signature-Input", strings.Replace(sigInput, `("@method" "@authority")`, `("@method" "@authority" "content-digest")`, 1))
+	}
+
+	tests := []struct {
+		name     string
+		metadata verifier.KeyMetadata
+		opts     []signer.Option
+		tamper   func(*http.Request)
+		err      error
+	}{
+		{"no constraints", verifier.KeyMetadata{}, nil, nil, nil},
+		{"allowed algorithm", verifier.KeyMetadata{Algorithms: []string{httpsig.AlgorithmEcdsaP256Sha256}}, nil, nil, nil},
+		{"disallowed algorithm", verifier.KeyMetadata{Algorithms: []string{httpsig.AlgorithmEd25519}}, nil, nil, verifier.ErrKeyConstraint},
+		{"allowed tag", verifier.KeyMetadata{Tags: []string{"web-bot-auth", "app"}}, []signer.Option{signer.WithTag("app")}, nil, nil},
+		{"disallowed tag", verifier.KeyMetadata{Tags: []string{"web-bot-auth"}}, []signer.Option{signer.WithTag("app")}, nil, verifier.ErrKeyConstraint},
+		{"missing tag", verifier.KeyMetadata{Tags: []string{"web-bot-auth"}}, nil, nil, verifier.ErrKeyConstraint},
+		{"covered components", verifier.KeyMetadata{RequiredComponents: httpsig.NewComponentIdentifiers(httpsig.DerivedComponentMethod, httpsig.DerivedComponentAuthority)}, nil, nil, nil},
+		{"uncovered component", verifier.KeyMetadata{RequiredComponents: httpsig.NewComponentIdentifiers(httpsig.DerivedComponentMethod, "content-digest")}, nil, nil, verifier.ErrKeyConstraint},
+		{"claimed but missing component", verifier.KeyMetadata{RequiredComponents: httpsig.NewComponentIdentifiers(httpsig.DerivedComponentMethod, "content-digest")}, nil, addContentDigest, httpsig.ErrHeaderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := verifier.NewInMemoryKeyResolver()
+			r.AddKeyWithMetadata(ECCP256TestKeyId, verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), tt.metadata)
+
+			v, err := verifier.NewWithKeyResolver(r, verifier.WithSigLabel("sig1"))
+			assert.NoError(t, err)
+
+			req := newSignedRequest(t, tt.opts...)
+			if tt.tamper != nil {
+				tt.tamper(req)
+			}
+
+			_, err = v.VerifyRequest(req)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNewKeyMetadataResolver(t *testing.T) {
+	// any KeyResolver, here one which ignores the keyid
+	resolver := verifier.KeyResolverFunc(func(ctx context.Context, params verifier.KeyParameters) (verifier.VerifyingAlgorithm, error) {
+		return verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), nil
+	})
+
+	r := verifier.NewKeyMetadataResolver(resolver, func(ctx context.Context, params verifier.KeyParameters, alg verifier.VerifyingAlgorithm) (verifier.KeyMetadata, error) {
+		if params.KeyId != ECCP256TestKeyId {
+			return verifier.KeyMetadata{}, fmt.Errorf("%w: keyid %q", verifier.ErrKeyNotFound, params.KeyId)
+		}
+		return verifier.KeyMetadata{Tags: []string{"app"}}, nil
+	})
+
+	v, err := verifier.NewWithKeyResolver(r, verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(newSignedRequest(t, signer.WithTag("app")))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(newSignedRequest(t))
+	assert.ErrorIs(t, err, verifier.ErrKeyConstraint)
+
+	_, err = v.VerifyRequest(newSignedRequest(t, signer.WithTag("app"), signer.WithKeyId("unknown")))
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_WithKeyMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata verifier.KeyMetadata
+		valid    bool
+	}{
+		{"allowed algorithm", verifier.KeyMetadata{Algorithms: []string{httpsig.AlgorithmEcdsaP256Sha256}}, true},
+		{"disallowed algorithm", verifier.KeyMetadata{Algorithms: []string{httpsig.AlgorithmEcdsaP384Sha384}}, false},
+		{"uncovered component", verifier.KeyMetadata{RequiredComponents: httpsig.NewComponentIdentifiers("content-digest")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the algorithm is created from the alg signature parameter
+			v, err := verifier.NewWithKey(&ECCP256TestKey.PublicKey, verifier.WithSigLabel("sig1"), verifier.WithKeyMetadata(tt.metadata))
+			assert.NoError(t, err)
+
+			_, err = v.VerifyRequest(newSignedRequest(t, signer.WithAlg()))
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, verifier.ErrKeyConstraint)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package verifier
+
+import (
+	"github.com/ccldd/httpsig"
+)
+
+// ParseVerifyingAlgorithm parses a PEM or DER encoded public key or certificate
+// with httpsig.ParsePublicKey and creates the verifying algorithm for it.
+// The algorithm is chosen with httpsig.DefaultAlgorithmName, use
+// NewVerifyingAlgorithm with the parsed key for any other algorithm.
+func ParseVerifyingAlgorithm(b []byte) (VerifyingAlgorithm, error) {
+	key, err := httpsig.ParsePublicKey(b)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := httpsig.DefaultAlgorithmName(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVerifyingAlgorithm(name, key)
+}
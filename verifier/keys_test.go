@@ -0,0 +1,31 @@
+package verifier_test
+
+import (
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVerifyingAlgorithm(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		signatureBase string
+		signature     string
+	}{
+		{httpsig.AlgorithmRsaPssSha512, RsaPssTestPublicKey, RsaPssMinimalSignatureBase, RsaPssMinimalSignature},
+		{httpsig.AlgorithmEcdsaP256Sha256, EcdsaP256TestPublicKey, EcdsaP256SignatureBase, EcdsaP256Signature},
+		{httpsig.AlgorithmEd25519, Ed25519TestPublicKey, Ed25519SignatureBase, Ed25519Signature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alg, err := verifier.ParseVerifyingAlgorithm([]byte(tt.key))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.name, alg.Name())
+			assert.True(t, alg.Verify([]byte(tt.signatureBase), mustDecodeBase64(t, tt.signature)))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package verifier
+
+import (
+	"time"
+
+	"github.com/ccldd/httpsig"
+)
+
+type Option func(*HttpMessageVerifier)
+
+// WithSigLabel verifies the signature with the sigLabel
+func WithSigLabel(sigLabel string) Option {
+	return func(hmv *HttpMessageVerifier) {
+		hmv.sigLabel = sigLabel
+	}
+}
+
+// WithFirstSignature verifies the first signature in the message
+func WithFirstSignature() Option {
+	return func(hmv *HttpMessageVerifier) {
+		hmv.validateFirstSignature = true
+	}
+}
+
+// WithOnlySignature verifies the signature only if
+// it is the only signature in the message
+func WithOnlySignature() Option {
+	return func(hmv *HttpMessageVerifier) {
+		hmv.validateIfOnlyOneSignature = true
+	}
+}
+
+// WithCreatedTolerance allows the created signature parameter
+// to be in the future by at most d to account for clock skew
+func WithCreatedTolerance(d time.Duration) Option {
+	return func(hmv *HttpMessageVerifier) {
+		hmv.createdTolerance = d
+	}
+}
+
+// WithExpiredTolerance allows the expires signature parameter
+// to be in the past by at most d to account for clock skew
+func WithExpiredTolerance(d time.Duration) Option {
+	return func(hmv *HttpMessageVerifier) {
+		hmv.expiredTolerance = d
+	}
+}
+
+// WithKeyMetadata constrains the signatures verified with any key,
+// in addition to the KeyMetadata from a KeyMetadataResolver
+func WithKeyMetadata(metadata KeyMetadata) Option {
+	return func(hmv *HttpMessageVerifier) {
+		hmv.metadata = metadata
+	}
+}
+
+// WithStructuredFieldRegistry sets the registry of the structured fields
+// covered with the "sf" parameter instead of httpsig.DefaultStructuredFieldRegistry
+func WithStructuredFieldRegistry(r *httpsig.StructuredFieldRegistry) Option {
+	return func(hmv *HttpMessageVerifier) {
+		hmv.structuredFields = r
+	}
+}
+
+// WithAlgorithmRegistry sets the registry used to resolve the alg
+// signature parameter instead of httpsig.DefaultAlgorithmRegistry
+func WithAlgorithmRegistry(r *httpsig.AlgorithmRegistry) Option {
+	return func(hmv *HttpMessageVerifier) {
+		hmv.registry = r
+	}
+}
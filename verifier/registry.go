@@ -0,0 +1,102 @@
+package verifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"reflect"
+
+	"github.com/ccldd/httpsig"
+)
+
+func init() {
+	RegisterAlgorithms(httpsig.DefaultAlgorithmRegistry)
+}
+
+// RegisterAlgorithms registers the built-in verifying algorithms in r
+// except rsa-v1_5-sha256 which has to be opted into with RegisterLegacyAlgorithms.
+//
+// The expected key types are *ecdsa.PublicKey, *rsa.PublicKey,
+// ed25519.PublicKey and []byte for hmac-sha256.
+func RegisterAlgorithms(r *httpsig.AlgorithmRegistry) {
+	r.RegisterVerifying(httpsig.AlgorithmEcdsaP256Sha256, verifyingFactory(httpsig.AlgorithmEcdsaP256Sha256, func(key *ecdsa.PublicKey) (VerifyingAlgorithm, error) {
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("key is not on the P256 curve")
+		}
+		return NewEcdsaSha256VerifyingAlgorithm(key), nil
+	}))
+	r.RegisterVerifying(httpsig.AlgorithmEcdsaP384Sha384, verifyingFactory(httpsig.AlgorithmEcdsaP384Sha384, func(key *ecdsa.PublicKey) (VerifyingAlgorithm, error) {
+		if key.Curve != elliptic.P384() {
+			return nil, fmt.Errorf("key is not on the P384 curve")
+		}
+		return NewEcdsaSha384VerifyingAlgorithm(key), nil
+	}))
+	r.RegisterVerifying(httpsig.AlgorithmRsaPssSha512, verifyingFactory(httpsig.AlgorithmRsaPssSha512, func(key *rsa.PublicKey) (VerifyingAlgorithm, error) {
+		return NewRsaPssSha512VerifyingAlgorithm(key)
+	}))
+	r.RegisterVerifying(httpsig.AlgorithmEd25519, verifyingFactory(httpsig.AlgorithmEd25519, func(key ed25519.PublicKey) (VerifyingAlgorithm, error) {
+		return NewEd25519VerifyingAlgorithm(key)
+	}))
+	r.RegisterVerifying(httpsig.AlgorithmHmacSha256, verifyingFactory(httpsig.AlgorithmHmacSha256, func(key []byte) (VerifyingAlgorithm, error) {
+		return NewHmacSha256VerifyingAlgorithm(key)
+	}))
+}
+
+// RegisterLegacyAlgorithms opts into rsa-v1_5-sha256 in r
+func RegisterLegacyAlgorithms(r *httpsig.AlgorithmRegistry) {
+	r.RegisterVerifying(httpsig.AlgorithmRsaV15Sha256, verifyingFactory(httpsig.AlgorithmRsaV15Sha256, func(key *rsa.PublicKey) (VerifyingAlgorithm, error) {
+		return NewLegacyRsaV15Sha256VerifyingAlgorithm(key)
+	}))
+}
+
+// NewVerifyingAlgorithm creates the verifying algorithm registered
+// as name in httpsig.DefaultAlgorithmRegistry
+func NewVerifyingAlgorithm(name string, key any) (VerifyingAlgorithm, error) {
+	return newVerifyingAlgorithm(httpsig.DefaultAlgorithmRegistry, name, key)
+}
+
+func newVerifyingAlgorithm(r *httpsig.AlgorithmRegistry, name string, key any) (VerifyingAlgorithm, error) {
+	alg, err := r.NewVerifying(name, key)
+	if err != nil {
+		return nil, err
+	}
+
+	verifyingAlg, ok := alg.(VerifyingAlgorithm)
+	if !ok {
+		return nil, fmt.Errorf("algorithm %q is not a VerifyingAlgorithm", name)
+	}
+
+	return verifyingAlg, nil
+}
+
+func verifyingFactory[K any](name string, newAlg func(K) (VerifyingAlgorithm, error)) httpsig.AlgorithmFactory {
+	return func(key any) (httpsig.Algorithm, error) {
+		if isNilKey(key) {
+			return nil, fmt.Errorf("algorithm %q: %w", name, httpsig.ErrNilKey)
+		}
+
+		k, ok := key.(K)
+		if !ok {
+			return nil, fmt.Errorf("algorithm %q does not support key type %T", name, key)
+		}
+
+		return newAlg(k)
+	}
+}
+
+// isNilKey reports whether key is nil or a typed nil
+// which would make the algorithm constructors panic
+func isNilKey(key any) bool {
+	if key == nil {
+		return true
+	}
+
+	switch v := reflect.ValueOf(key); v.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
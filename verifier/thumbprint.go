@@ -0,0 +1,68 @@
+package verifier
+
+import (
+	"crypto"
+	"fmt"
+
+	"github.com/ccldd/httpsig"
+)
+
+// NewThumbprintKeyResolver creates an InMemoryKeyResolver with the public keys, or the public keys of private keys,
+// using their SHA-256 JWK thumbprint as the keyid. The algorithm of each key is
+// chosen with httpsig.DefaultAlgorithmName.
+//
+// https://datatracker.ietf.org/doc/html/rfc7638
+func NewThumbprintKeyResolver(keys ...any) (*InMemoryKeyResolver, error) {
+	r := NewInMemoryKeyResolver()
+	for i, key := range keys {
+		thumbprint, err := httpsig.JWKThumbprint(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+
+		name, err := httpsig.DefaultAlgorithmName(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+
+		if signer, ok := key.(crypto.Signer); ok {
+			key = signer.Public()
+		}
+
+		alg, err := NewVerifyingAlgorithm(name, key)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		r.AddKey(thumbprint, alg)
+	}
+
+	return r, nil
+}
+
+// NewThumbprintKeyResolverFromJWKSet creates an InMemoryKeyResolver with the keys
+// in set using their SHA-256 JWK thumbprint as the keyid instead of the kid.
+// Keys for encryption are skipped.
+func NewThumbprintKeyResolverFromJWKSet(set httpsig.JWKSet) (*InMemoryKeyResolver, error) {
+	r := NewInMemoryKeyResolver()
+	for i, jwk := range set.Keys {
+		if jwk.Use == "enc" {
+			continue
+		}
+		if jwk.KeyType == httpsig.JWKKeyTypeOct {
+			return nil, fmt.Errorf("JWK %d: thumbprints of shared secrets are not supported", i)
+		}
+
+		thumbprint, err := jwk.Thumbprint()
+		if err != nil {
+			return nil, fmt.Errorf("JWK %d: %w", i, err)
+		}
+
+		alg, err := NewVerifyingAlgorithmFromJWK(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("JWK %d: %w", i, err)
+		}
+		r.AddKey(thumbprint, alg)
+	}
+
+	return r, nil
+}
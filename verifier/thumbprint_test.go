@@ -0,0 +1,38 @@
+package verifier_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewThumbprintKeyResolver(t *testing.T) {
+	req := newSignedRequest(t, signer.WithJWKThumbprintKeyId())
+
+	set, err := httpsig.ParseJWKSet([]byte(TestJWKSet))
+	assert.NoError(t, err)
+
+	fromKeys, err := verifier.NewThumbprintKeyResolver(&ECCP256TestKey.PublicKey)
+	assert.NoError(t, err)
+	fromJWKSet, err := verifier.NewThumbprintKeyResolverFromJWKSet(set)
+	assert.NoError(t, err)
+
+	for _, resolver := range []verifier.KeyResolver{fromKeys, fromJWKSet} {
+		v, err := verifier.NewWithKeyResolver(resolver, verifier.WithSigLabel("sig1"))
+		assert.NoError(t, err)
+
+		_, err = v.VerifyRequest(req)
+		assert.NoError(t, err)
+
+		// the kid is not the keyid
+		_, err = resolver.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: ECCP256TestKeyId})
+		assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+	}
+
+	_, err = verifier.NewThumbprintKeyResolver([]byte("secret"))
+	assert.Error(t, err)
+}
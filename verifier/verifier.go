@@ -1,9 +1,11 @@
 package verifier
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/ccldd/httpsig"
@@ -17,8 +19,19 @@ type VerifyResult struct {
 	signatureInput httpsig.SignatureInput
 }
 
+var (
+	ErrNoSigLabel   = errors.New("missing sigLabel")
+	ErrNoAlgorithm  = errors.New("missing verifying algorithm, key or key resolver")
+	ErrAlgParameter = errors.New("alg signature parameter is required to resolve the algorithm")
+	ErrAlgMismatch  = errors.New("alg signature parameter does not match the verifying algorithm")
+)
+
 type HttpMessageVerifier struct {
-	alg VerifyingAlgorithm
+	resolver KeyResolver
+	registry *httpsig.AlgorithmRegistry
+	metadata KeyMetadata
+
+	structuredFields *httpsig.StructuredFieldRegistry
 
 	sigLabel                   string
 	validateFirstSignature     bool
@@ -28,6 +41,71 @@ type HttpMessageVerifier struct {
 	expiredTolerance time.Duration
 }
 
+func (hmv HttpMessageVerifier) validate() error {
+	errs := make([]error, 0)
+
+	if hmv.sigLabel == "" && !hmv.validateFirstSignature && !hmv.validateIfOnlyOneSignature {
+		errs = append(errs, ErrNoSigLabel)
+	}
+	if hmv.resolver == nil {
+		errs = append(errs, ErrNoAlgorithm)
+	}
+
+	return errors.Join(errs...)
+}
+
+func newHttpMessageVerifier(opts ...Option) *HttpMessageVerifier {
+	verifier := &HttpMessageVerifier{
+		registry:         httpsig.DefaultAlgorithmRegistry,
+		structuredFields: httpsig.DefaultStructuredFieldRegistry,
+	}
+	for _, opt := range opts {
+		opt(verifier)
+	}
+
+	return verifier
+}
+
+// New creates a HttpMessageVerifier which always verifies using alg
+func New(alg VerifyingAlgorithm, opts ...Option) (*HttpMessageVerifier, error) {
+	v := newHttpMessageVerifier(opts...)
+	if alg != nil {
+		v.resolver = staticKeyResolver{alg: alg}
+	}
+
+	if err := v.validate(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NewWithKey creates a HttpMessageVerifier which creates the verifying
+// algorithm from key and the alg signature parameter using the algorithm registry.
+// Signatures without the alg signature parameter are rejected.
+func NewWithKey(key any, opts ...Option) (*HttpMessageVerifier, error) {
+	v := newHttpMessageVerifier(opts...)
+	if key != nil {
+		v.resolver = registryKeyResolver{key: key, registry: v.registry}
+	}
+
+	if err := v.validate(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NewWithKeyResolver creates a HttpMessageVerifier which looks up
+// the verifying algorithm with resolver for every signature
+func NewWithKeyResolver(resolver KeyResolver, opts ...Option) (*HttpMessageVerifier, error) {
+	v := newHttpMessageVerifier(opts...)
+	v.resolver = resolver
+
+	if err := v.validate(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
 func (hmv *HttpMessageVerifier) VerifyRequest(req *http.Request) (res VerifyResult, err error) {
 	msg := httpsig.HttpRequest{Request: req}
 
@@ -51,29 +129,54 @@ func (hmv *HttpMessageVerifier) VerifyRequest(req *http.Request) (res VerifyResu
 	// Parse and validate the signature parameters
 	sigParamErrs := make([]error, 0)
 	sigParams := sigInput.SignatureParameters()
-	for _, p := range sigParams {
+	for i, p := range sigParams {
 		switch pp := p.(type) {
 		case httpsig.Created:
 			pp.Tolerance = hmv.createdTolerance
+			sigParams[i] = pp
 		case httpsig.Expires:
 			pp.Tolerance = hmv.expiredTolerance
+			sigParams[i] = pp
 		}
 
-		sigParamErrs = append(sigParamErrs, p.Validate())
+		sigParamErrs = append(sigParamErrs, sigParams[i].Validate())
 	}
 	if err = errors.Join(sigParamErrs...); err != nil {
 		err = fmt.Errorf("error verifying: %w", err)
 		return
 	}
 
+	alg, metadata, err := hmv.verifyingAlgorithm(req.Context(), sigParams)
+	if err != nil {
+		err = fmt.Errorf("error verifying: %w", err)
+		return
+	}
+
+	components, err := sigInput.Components()
+	if err != nil {
+		err = fmt.Errorf("error verifying: %w", err)
+		return
+	}
+
 	// Create the signature base
-	components := sigInput.Components()
-	sigBase, err := httpsig.NewSignatureBaseFromRequest(msg, components, sigParams)
+	sigBase, err := httpsig.NewSignatureBaseFromRequestWithRegistry(msg, components, sigParams, hmv.structuredFields)
 	if err != nil {
 		err = fmt.Errorf("error verifying: %w", err)
 		return
 	}
 
+	// the key constraints are validated against the components
+	// which are actually signed
+	keyParams := KeyParametersFromSignatureParameters(sigParams)
+	if err = errors.Join(
+		validateCoveredComponents(components, sigBase.Components),
+		metadata.Validate(alg, keyParams, sigBase.Components),
+		hmv.metadata.Validate(alg, keyParams, sigBase.Components),
+	); err != nil {
+		err = fmt.Errorf("error verifying: %w", err)
+		return
+	}
+
 	sigBaseStr, err := sigBase.Marshal()
 	if err != nil {
 		err = fmt.Errorf("error verifying: %w", err)
@@ -84,22 +187,45 @@ func (hmv *HttpMessageVerifier) VerifyRequest(req *http.Request) (res VerifyResu
 	sigBytes, err := signature.Bytes()
 	if err != nil {
 		err = fmt.Errorf("error verifying: %w", err)
+		return
 	}
 
-	if sigValid := hmv.alg.Verify(sigBaseBytes, sigBytes); !sigValid {
+	if sigValid := alg.Verify(sigBaseBytes, sigBytes); !sigValid {
 		err = fmt.Errorf("error verifying: expected signature does not match actual signature")
 	}
 
 	return
 }
 
+// validateCoveredComponents returns an error for every component listed
+// in Signature-Input which is not in the signature base. Signing skips
+// missing headers, but a verifier must fail if a covered component
+// cannot be resolved.
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#section-3.2
+func validateCoveredComponents(covered []httpsig.ComponentIdentifier, signed []httpsig.ComponentIdentifier) error {
+	errs := make([]error, 0)
+	for _, c := range covered {
+		if !slices.ContainsFunc(signed, c.Equal) {
+			errs = append(errs, fmt.Errorf("covered component %s: %w", c, httpsig.ErrHeaderNotFound))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func (hmv *HttpMessageVerifier) getSignatureToVerify(msg httpsig.SignedHttpMessage) (sig httpsig.SignatureHeaderValue, sigLabel string, err error) {
 	sigLabels := msg.SigLabels()
 
 	switch {
 	case hmv.validateIfOnlyOneSignature && len(sigLabels) > 1:
 		err = fmt.Errorf("multiple signatures found: %v", sigLabels)
+	case hmv.validateIfOnlyOneSignature && len(sigLabels) == 0,
+		hmv.validateFirstSignature && len(sigLabels) == 0:
+		err = fmt.Errorf("no signature found")
 	case hmv.validateIfOnlyOneSignature:
+		sigLabel = sigLabels[0]
+		sig, err = msg.GetSignature(sigLabel)
 	case hmv.validateFirstSignature:
 		sigLabel = sigLabels[0]
 		sig, err = msg.GetSignature(sigLabel)
@@ -112,3 +238,38 @@ func (hmv *HttpMessageVerifier) getSignatureToVerify(msg httpsig.SignedHttpMessa
 
 	return
 }
+
+// verifyingAlgorithm resolves the algorithm and the key metadata from the
+// signature parameters. If the signature has the alg signature parameter,
+// it must match the name of the resolved algorithm.
+//
+// https://datatracker.ietf.org/doc/html/rfc9421#section-3.2-4.6
+func (hmv *HttpMessageVerifier) verifyingAlgorithm(ctx context.Context, sigParams []httpsig.SignatureParameter) (alg VerifyingAlgorithm, metadata KeyMetadata, err error) {
+	params := KeyParametersFromSignatureParameters(sigParams)
+
+	if resolver, ok := hmv.resolver.(KeyMetadataResolver); ok {
+		alg, metadata, err = resolver.ResolveKeyMetadata(ctx, params)
+	} else {
+		alg, err = hmv.resolver.ResolveKey(ctx, params)
+	}
+	if err != nil {
+		return
+	}
+	if alg == nil {
+		err = fmt.Errorf("%w: keyid %q", ErrKeyNotFound, params.KeyId)
+		return
+	}
+
+	// JWS algorithms are agreed on out of band and never advertised
+	//
+	// https://datatracker.ietf.org/doc/html/rfc9421#section-3.3.7
+	if params.Alg != "" && (httpsig.IsJWSAlgorithm(alg.Name()) || httpsig.IsJWSAlgorithm(params.Alg)) {
+		err = httpsig.ErrJWSAlgParameter
+		return
+	}
+	if params.Alg != "" && params.Alg != alg.Name() {
+		err = fmt.Errorf("%w: got %q, expected %q", ErrAlgMismatch, params.Alg, alg.Name())
+	}
+
+	return
+}
@@ -1,8 +1,295 @@
 package verifier_test
 
-import "testing"
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	ECCP256TestKeyId = "test-key-ecc-p256"
+)
+
+var ECCP256TestKey *ecdsa.PrivateKey
 
 func TestMain(m *testing.M) {
+	var err error
+
+	bytes, err := base64.StdEncoding.DecodeString("MHcCAQEEIFKbhfNZfpDsW43+0+JjUr9K+bTeuxopu653+hBaXGA7oAoGCCqGSM49AwEHoUQDQgAEqIVYZVLCrPZHGHjP17CTW0/+D9Lfw0EkjqF7xB4FivAxzic30tMM4GF+hR6Dxh71Z50VGGdldkkDXZCnTNnoXQ==")
+	if err != nil {
+		panic(err)
+	}
+
+	ECCP256TestKey, err = x509.ParseECPrivateKey(bytes)
+	if err != nil {
+		panic(err)
+	}
 
 	m.Run()
 }
+
+func newSignedRequest(t *testing.T, opts ...signer.Option) *http.Request {
+	t.Helper()
+
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(t, err)
+
+	opts = append([]signer.Option{signer.WithCreated(), signer.WithKeyId(ECCP256TestKeyId), signer.WithMethod(), signer.WithAuthority()}, opts...)
+	s, err := signer.New(alg, "sig1", opts...)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/foo?param=Value", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, s.SignRequest(req))
+
+	return req
+}
+
+func TestHttpMessageVerifier_VerifyRequest(t *testing.T) {
+	req := newSignedRequest(t)
+
+	v, err := verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	req.Method = http.MethodGet
+	_, err = v.VerifyRequest(req)
+	assert.Error(t, err)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_Registry(t *testing.T) {
+	v, err := verifier.NewWithKey(&ECCP256TestKey.PublicKey, verifier.WithOnlySignature())
+	assert.NoError(t, err)
+
+	req := newSignedRequest(t, signer.WithCustomAlg(httpsig.AlgorithmEcdsaP256Sha256))
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	// the key cannot be used with another algorithm
+	req = newSignedRequest(t, signer.WithCustomAlg(httpsig.AlgorithmEd25519))
+	_, err = v.VerifyRequest(req)
+	assert.Error(t, err)
+
+	// the algorithm cannot be resolved without alg
+	req = newSignedRequest(t)
+	_, err = v.VerifyRequest(req)
+	assert.ErrorIs(t, err, verifier.ErrAlgParameter)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_InvalidSignature(t *testing.T) {
+	req := newSignedRequest(t)
+	req.Header.Set(httpsig.HeaderSignature, "sig1=abc")
+
+	v, err := verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(req)
+	assert.ErrorContains(t, err, "error reading signature bytes")
+}
+
+func TestHttpMessageVerifier_VerifyRequest_InvalidSignatureInput(t *testing.T) {
+	req := newSignedRequest(t)
+	sigInput := req.Header.Get(httpsig.HeaderSignatureInput)
+	req.Header.Set(httpsig.HeaderSignatureInput, strings.Replace(sigInput, `"@method"`, `"@method" 1`, 1))
+
+	v, err := verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(req)
+	assert.ErrorIs(t, err, httpsig.ErrInvalidSignatureInput)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_CreatedTolerance(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/foo", nil)
+	assert.NoError(t, err)
+
+	// created is in the future because of clock skew
+	sigParams := []httpsig.SignatureParameter{httpsig.Created{Time: time.Now().Add(time.Minute)}}
+	sigBase, err := httpsig.NewSignatureBaseFromRequest(httpsig.HttpRequest{Request: req}, httpsig.NewComponentIdentifiers(httpsig.DerivedComponentMethod), sigParams)
+	assert.NoError(t, err)
+	sigBaseStr, err := sigBase.Marshal()
+	assert.NoError(t, err)
+
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(t, err)
+	sig, err := alg.Sign([]byte(sigBaseStr))
+	assert.NoError(t, err)
+
+	sigInput, err := httpsig.SignatureInputFromSignatureParams("sig1", &sigBase.SignatureParams).Marshal()
+	assert.NoError(t, err)
+	sigHeader, err := httpsig.NewSignatureHeaderValue("sig1", sig).Marshal()
+	assert.NoError(t, err)
+	req.Header.Set(httpsig.HeaderSignatureInput, sigInput)
+	req.Header.Set(httpsig.HeaderSignature, sigHeader)
+
+	v, err := verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+	_, err = v.VerifyRequest(req)
+	assert.Error(t, err)
+
+	v, err = verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithSigLabel("sig1"), verifier.WithCreatedTolerance(2*time.Minute))
+	assert.NoError(t, err)
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_OnlySignature(t *testing.T) {
+	v, err := verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithOnlySignature())
+	assert.NoError(t, err)
+
+	req := newSignedRequest(t)
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	req.Header.Add(httpsig.HeaderSignature, "sig2=:YWJj:")
+	_, err = v.VerifyRequest(req)
+	assert.Error(t, err)
+
+	req.Header.Del(httpsig.HeaderSignature)
+	_, err = v.VerifyRequest(req)
+	assert.Error(t, err)
+
+	v, err = verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithFirstSignature())
+	assert.NoError(t, err)
+	_, err = v.VerifyRequest(req)
+	assert.Error(t, err)
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey))
+	assert.ErrorIs(t, err, verifier.ErrNoSigLabel)
+
+	_, err = verifier.New(nil, verifier.WithFirstSignature())
+	assert.ErrorIs(t, err, verifier.ErrNoAlgorithm)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_AlgMismatch(t *testing.T) {
+	v, err := verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	req := newSignedRequest(t, signer.WithAlg())
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	req = newSignedRequest(t, signer.WithCustomAlg(httpsig.AlgorithmEcdsaP384Sha384))
+	_, err = v.VerifyRequest(req)
+	assert.ErrorIs(t, err, verifier.ErrAlgMismatch)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_QueryParam(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc9421#name-selective-covered-component
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/foo?param=Value&Pet=dog", strings.NewReader(`{"hello": "world"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Digest", "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:")
+	req.Header.Set(httpsig.HeaderSignatureInput, `sig-b22=("@authority" "content-digest" "@query-param";name="Pet");created=1618884473;keyid="test-key-rsa-pss";tag="header-example"`)
+	req.Header.Set(httpsig.HeaderSignature, "sig-b22=:"+RsaPssSelectiveSignature+":")
+
+	alg, err := verifier.NewRsaPssSha512VerifyingAlgorithm(mustParsePublicKey(t, RsaPssTestPublicKey).(*rsa.PublicKey))
+	assert.NoError(t, err)
+
+	v, err := verifier.New(alg, verifier.WithSigLabel("sig-b22"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	// the signature covers the Pet query param
+	req.URL.RawQuery = "param=Value&Pet=cat"
+	_, err = v.VerifyRequest(req)
+	assert.Error(t, err)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_StructuredField(t *testing.T) {
+	r := httpsig.NewStructuredFieldRegistry()
+	r.Register("Example-Dict", httpsig.StructuredFieldDictionary)
+
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(t, err)
+
+	s, err := signer.New(alg, "sig1", signer.WithHeaders("Example-Dict"), signer.WithStructuredFields("Example-Dict"), signer.WithStructuredFieldRegistry(r))
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/foo", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Example-Dict", "a=1,    b=2;x=1;y=2,   c=(a   b   c)")
+	assert.NoError(t, s.SignRequest(req))
+
+	// the verifier needs the same registry
+	v, err := verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+	_, err = v.VerifyRequest(req)
+	assert.ErrorIs(t, err, httpsig.ErrUnknownStructuredField)
+
+	v, err = verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithSigLabel("sig1"), verifier.WithStructuredFieldRegistry(r))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	s, err = signer.New(alg, "sig1", signer.WithStructuredFields("Example-Dict"), signer.WithStructuredFieldRegistry(r))
+	assert.NoError(t, err)
+	req.Header.Del(httpsig.HeaderSignature)
+	req.Header.Del(httpsig.HeaderSignatureInput)
+	assert.NoError(t, s.SignRequest(req))
+
+	// a proxy re-encodes the header
+	req.Header.Set("Example-Dict", "a=1, b=2;x=1;y=2, c=(a b c)")
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	req.Header.Set("Example-Dict", "a=2, b=2;x=1;y=2, c=(a b c)")
+	_, err = v.VerifyRequest(req)
+	assert.Error(t, err)
+}
+
+func TestHttpMessageVerifier_VerifyRequest_DictionaryMember(t *testing.T) {
+	alg, err := signer.NewEcdsaSha256(ECCP256TestKey)
+	assert.NoError(t, err)
+
+	s, err := signer.New(alg, "sig1", signer.WithMethod(), signer.WithDictionaryMembers("Example-Dict", "a"))
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/foo", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Example-Dict", "a=1, b=2")
+	assert.NoError(t, s.SignRequest(req))
+
+	v, err := verifier.New(verifier.NewEcdsaSha256VerifyingAlgorithm(&ECCP256TestKey.PublicKey), verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	// only the a member is covered
+	req.Header.Set("Example-Dict", "b=3,   a=1")
+	_, err = v.VerifyRequest(req)
+	assert.NoError(t, err)
+
+	req.Header.Set("Example-Dict", "a=2, b=2")
+	_, err = v.VerifyRequest(req)
+	assert.Error(t, err)
+
+	req.Header.Set("Example-Dict", "b=2")
+	_, err = v.VerifyRequest(req)
+	assert.ErrorIs(t, err, httpsig.ErrDictionaryMemberNotFound)
+
+	req.Header.Set("Example-Dict", "a=(")
+	_, err = v.VerifyRequest(req)
+	assert.ErrorIs(t, err, httpsig.ErrInvalidDictionary)
+
+	req.Header.Del("Example-Dict")
+	_, err = v.VerifyRequest(req)
+	assert.ErrorIs(t, err, httpsig.ErrDictionaryMemberNotFound)
+}
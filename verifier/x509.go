@@ -0,0 +1,169 @@
+package verifier
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ccldd/httpsig"
+)
+
+var (
+	ErrInvalidCertificate = errors.New("invalid certificate")
+	ErrNoRootCertificates = errors.New("no root certificates")
+)
+
+type X509Option func(*X509KeyResolver)
+
+// WithExtKeyUsages sets the extended key usages the certificates must be valid for.
+// By default any extended key usage is accepted.
+func WithExtKeyUsages(usages ...x509.ExtKeyUsage) X509Option {
+	return func(r *X509KeyResolver) {
+		r.extKeyUsages = usages
+	}
+}
+
+// WithCurrentTime sets the function returning the time certificates are validated at
+func WithCurrentTime(now func() time.Time) X509Option {
+	return func(r *X509KeyResolver) {
+		r.now = now
+	}
+}
+
+// WithX509AlgorithmRegistry sets the registry used to create the algorithms
+// instead of httpsig.DefaultAlgorithmRegistry, e.g. a registry with
+// RegisterLegacyAlgorithms to accept rsa-v1_5-sha256
+func WithX509AlgorithmRegistry(registry *httpsig.AlgorithmRegistry) X509Option {
+	return func(r *X509KeyResolver) {
+		r.registry = registry
+	}
+}
+
+// X509KeyResolver is a KeyResolver which maps keyids to X.509 certificates.
+// Every time a key is resolved, the certificate chain is validated against the
+// roots, the validity period is checked, and the leaf certificate must allow
+// digital signatures. The verifying algorithm uses the public key of the leaf
+// and the alg signature parameter, or the default algorithm of the key without it.
+// It is safe for concurrent use.
+type X509KeyResolver struct {
+	roots        *x509.CertPool
+	registry     *httpsig.AlgorithmRegistry
+	extKeyUsages []x509.ExtKeyUsage
+	now          func() time.Time
+
+	mu    sync.RWMutex
+	certs map[string][]*x509.Certificate
+}
+
+// NewX509KeyResolver creates a X509KeyResolver trusting the certificates in roots.
+// The roots must not be nil or empty since the certificates would
+// otherwise be validated against the system trust store.
+func NewX509KeyResolver(roots *x509.CertPool, opts ...X509Option) (*X509KeyResolver, error) {
+	if roots == nil || roots.Equal(x509.NewCertPool()) {
+		return nil, ErrNoRootCertificates
+	}
+
+	r := &X509KeyResolver{
+		roots:        roots,
+		registry:     httpsig.DefaultAlgorithmRegistry,
+		extKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		now:          time.Now,
+		certs:        make(map[string][]*x509.Certificate),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// AddCertificate adds or replaces the certificate chain of keyId.
+// The first certificate is the leaf followed by the intermediates.
+func (r *X509KeyResolver) AddCertificate(keyId string, chain ...*x509.Certificate) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("%w: empty certificate chain", ErrInvalidCertificate)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.certs[keyId] = chain
+
+	return nil
+}
+
+// AddCertificatePEM adds or replaces the PEM encoded certificate chain of keyId.
+// The first certificate is the leaf followed by the intermediates.
+func (r *X509KeyResolver) AddCertificatePEM(keyId string, b []byte) error {
+	var chain []*x509.Certificate
+	for block, rest := pem.Decode(b); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidCertificate, err)
+		}
+		chain = append(chain, cert)
+	}
+
+	return r.AddCertificate(keyId, chain...)
+}
+
+// RemoveCertificate removes the certificate chain of keyId
+func (r *X509KeyResolver) RemoveCertificate(keyId string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.certs, keyId)
+}
+
+func (r *X509KeyResolver) ResolveKey(ctx context.Context, params KeyParameters) (VerifyingAlgorithm, error) {
+	r.mu.RLock()
+	chain, ok := r.certs[params.KeyId]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: keyid %q", ErrKeyNotFound, params.KeyId)
+	}
+
+	leaf := chain[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         r.roots,
+		Intermediates: intermediates,
+		CurrentTime:   r.now(),
+		KeyUsages:     r.extKeyUsages,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: keyid %q: %w", ErrInvalidCertificate, params.KeyId, err)
+	}
+
+	// certificates without the key usage extension can be used for anything
+	if leaf.KeyUsage != 0 && leaf.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return nil, fmt.Errorf("%w: keyid %q: certificate is not valid for digital signatures", ErrInvalidCertificate, params.KeyId)
+	}
+
+	name := params.Alg
+	if name == "" {
+		name, err = httpsig.DefaultAlgorithmName(leaf.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: keyid %q: %w", ErrInvalidCertificate, params.KeyId, err)
+		}
+	}
+
+	alg, err := newVerifyingAlgorithm(r.registry, name, leaf.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("keyid %q: %w", params.KeyId, err)
+	}
+
+	return alg, nil
+}
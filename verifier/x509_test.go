@@ -0,0 +1,226 @@
+package verifier_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ccldd/httpsig"
+	"github.com/ccldd/httpsig/signer"
+	"github.com/ccldd/httpsig/verifier"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+// newTestCertificate creates a certificate for pub signed by parent,
+// or a self-signed CA certificate if parent is nil
+func newTestCertificate(t *testing.T, template *x509.Certificate, pub crypto.PublicKey, parent *testCertificate) *testCertificate {
+	t.Helper()
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NoError(t, err)
+	template.SerialNumber = serial
+	if template.NotBefore.IsZero() {
+		template.NotBefore = time.Now().Add(-time.Hour)
+		template.NotAfter = time.Now().Add(time.Hour)
+	}
+
+	var key crypto.Signer
+	if pub == nil {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(t, err)
+		key, pub = ecKey, ecKey.Public()
+	}
+
+	issuer, issuerKey := template, key
+	if parent != nil {
+		issuer, issuerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, pub, issuerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return &testCertificate{cert: cert, key: key}
+}
+
+func newTestCA(t *testing.T, name string, parent *testCertificate) *testCertificate {
+	return newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, parent)
+}
+
+func newTestLeaf(t *testing.T, parent *testCertificate, keyUsage x509.KeyUsage, extKeyUsage ...x509.ExtKeyUsage) *x509.Certificate {
+	return newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: ECCP256TestKeyId},
+		KeyUsage:    keyUsage,
+		ExtKeyUsage: extKeyUsage,
+	}, &ECCP256TestKey.PublicKey, parent).cert
+}
+
+func TestX509KeyResolver(t *testing.T) {
+	root := newTestCA(t, "root", nil)
+	intermediate := newTestCA(t, "intermediate", root)
+	leaf := newTestLeaf(t, intermediate, x509.KeyUsageDigitalSignature, x509.ExtKeyUsageClientAuth)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+
+	r, err := verifier.NewX509KeyResolver(roots, verifier.WithExtKeyUsages(x509.ExtKeyUsageClientAuth))
+	assert.NoError(t, err)
+
+	var chain []byte
+	for _, cert := range []*x509.Certificate{leaf, intermediate.cert} {
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	assert.NoError(t, r.AddCertificatePEM(ECCP256TestKeyId, chain))
+
+	v, err := verifier.NewWithKeyResolver(r, verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(newSignedRequest(t))
+	assert.NoError(t, err)
+
+	// the intermediate is required to build the chain
+	assert.NoError(t, r.AddCertificate(ECCP256TestKeyId, leaf))
+	_, err = v.VerifyRequest(newSignedRequest(t))
+	assert.ErrorIs(t, err, verifier.ErrInvalidCertificate)
+
+	r.RemoveCertificate(ECCP256TestKeyId)
+	_, err = v.VerifyRequest(newSignedRequest(t))
+	assert.ErrorIs(t, err, verifier.ErrKeyNotFound)
+}
+
+func TestX509KeyResolver_Algorithm(t *testing.T) {
+	root := newTestCA(t, "root", nil)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	leaf := newTestCertificate(t, &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "rsa-client"},
+		KeyUsage: x509.KeyUsageDigitalSignature,
+	}, &rsaKey.PublicKey, root).cert
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+
+	signRequest := func(alg signer.SigningAlgorithm, opts ...signer.Option) *http.Request {
+		s, err := signer.New(alg, "sig1", append([]signer.Option{signer.WithKeyId("rsa-client"), signer.WithMethod()}, opts...)...)
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+		assert.NoError(t, err)
+		assert.NoError(t, s.SignRequest(req))
+		return req
+	}
+	pss, err := signer.NewRsaPssSha512SigningAlgorithm(rsaKey)
+	assert.NoError(t, err)
+	v15, err := signer.NewLegacyRsaV15Sha256SigningAlgorithm(rsaKey)
+	assert.NoError(t, err)
+
+	// without alg, RSA keys use rsa-pss-sha512
+	r, err := verifier.NewX509KeyResolver(roots)
+	assert.NoError(t, err)
+	assert.NoError(t, r.AddCertificate("rsa-client", leaf))
+	v, err := verifier.NewWithKeyResolver(r, verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(signRequest(pss))
+	assert.NoError(t, err)
+	_, err = v.VerifyRequest(signRequest(v15, signer.WithAlg()))
+	assert.ErrorIs(t, err, httpsig.ErrUnknownAlgorithm)
+
+	// the alg signature parameter selects the algorithm from the registry
+	registry := httpsig.NewAlgorithmRegistry()
+	verifier.RegisterAlgorithms(registry)
+	verifier.RegisterLegacyAlgorithms(registry)
+
+	r, err = verifier.NewX509KeyResolver(roots, verifier.WithX509AlgorithmRegistry(registry))
+	assert.NoError(t, err)
+	assert.NoError(t, r.AddCertificate("rsa-client", leaf))
+	v, err = verifier.NewWithKeyResolver(r, verifier.WithSigLabel("sig1"))
+	assert.NoError(t, err)
+
+	_, err = v.VerifyRequest(signRequest(v15, signer.WithAlg()))
+	assert.NoError(t, err)
+	_, err = v.VerifyRequest(signRequest(pss, signer.WithAlg()))
+	assert.NoError(t, err)
+}
+
+func TestX509KeyResolver_Invalid(t *testing.T) {
+	root := newTestCA(t, "root", nil)
+	untrusted := newTestCA(t, "untrusted", nil)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+
+	tests := []struct {
+		name string
+		leaf *x509.Certificate
+		opts []verifier.X509Option
+	}{
+		{
+			name: "untrusted root",
+			leaf: newTestLeaf(t, untrusted, x509.KeyUsageDigitalSignature),
+		},
+		{
+			name: "expired",
+			leaf: newTestLeaf(t, root, x509.KeyUsageDigitalSignature),
+			opts: []verifier.X509Option{verifier.WithCurrentTime(func() time.Time { return time.Now().Add(2 * time.Hour) })},
+		},
+		{
+			name: "not yet valid",
+			leaf: newTestLeaf(t, root, x509.KeyUsageDigitalSignature),
+			opts: []verifier.X509Option{verifier.WithCurrentTime(func() time.Time { return time.Now().Add(-2 * time.Hour) })},
+		},
+		{
+			name: "no digital signature key usage",
+			leaf: newTestLeaf(t, root, x509.KeyUsageKeyEncipherment),
+		},
+		{
+			name: "wrong extended key usage",
+			leaf: newTestLeaf(t, root, x509.KeyUsageDigitalSignature, x509.ExtKeyUsageServerAuth),
+			opts: []verifier.X509Option{verifier.WithExtKeyUsages(x509.ExtKeyUsageClientAuth)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := verifier.NewX509KeyResolver(roots, tt.opts...)
+			assert.NoError(t, err)
+			assert.NoError(t, r.AddCertificate(ECCP256TestKeyId, tt.leaf))
+
+			_, err = r.ResolveKey(context.Background(), verifier.KeyParameters{KeyId: ECCP256TestKeyId})
+			assert.ErrorIs(t, err, verifier.ErrInvalidCertificate)
+		})
+	}
+
+	r, err := verifier.NewX509KeyResolver(roots)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, r.AddCertificate(ECCP256TestKeyId), verifier.ErrInvalidCertificate)
+	assert.ErrorIs(t, r.AddCertificatePEM(ECCP256TestKeyId, []byte("no certificates")), verifier.ErrInvalidCertificate)
+}
+
+func TestNewX509KeyResolver_NoRoots(t *testing.T) {
+	_, err := verifier.NewX509KeyResolver(nil)
+	assert.ErrorIs(t, err, verifier.ErrNoRootCertificates)
+
+	_, err = verifier.NewX509KeyResolver(x509.NewCertPool())
+	assert.ErrorIs(t, err, verifier.ErrNoRootCertificates)
+}